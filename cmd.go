@@ -11,6 +11,10 @@ import (
 type SubCfg struct {
 	Headless   bool
 	ConfigPath string
+	Transport  string
+	ListenAddr string
+	BasePath   string
+	RemoteURL  string
 }
 
 func RunCmd() (*SubCfg, error) {
@@ -34,6 +38,29 @@ func RunCmd() (*SubCfg, error) {
 				Usage:       "use to enable headless,if false browser will shown window",
 				Destination: &subConfig.Headless,
 			},
+			&cli.StringFlag{
+				Name:        "transport",
+				Aliases:     []string{"t"},
+				Usage:       "use to set the transport of the server, one of `stdio`, `sse` and `streamable-http`",
+				Destination: &subConfig.Transport,
+			},
+			&cli.StringFlag{
+				Name:        "listen",
+				Aliases:     []string{"l"},
+				Usage:       "use to set the listen address of the sse and streamable-http transports, such as `0.0.0.0:8080`",
+				Destination: &subConfig.ListenAddr,
+			},
+			&cli.StringFlag{
+				Name:        "base-path",
+				Usage:       "use to set the path prefix of the sse and streamable-http endpoints",
+				Destination: &subConfig.BasePath,
+			},
+			&cli.StringFlag{
+				Name:        "remote-debugging-url",
+				Aliases:     []string{"r"},
+				Usage:       "use to drive an already running chrome instead of launching one, such as `localhost:9222` or its websocket url",
+				Destination: &subConfig.RemoteURL,
+			},
 			&cli.BoolFlag{
 				Name:    "no-banner",
 				Aliases: []string{"nb"},
@@ -29,6 +29,22 @@ func main() {
 	if subCfg.Headless {
 		cfg.Headless = true
 	}
+	if subCfg.Transport != "" {
+		cfg.Transport = subCfg.Transport
+	}
+	if subCfg.ListenAddr != "" {
+		cfg.ListenAddr = subCfg.ListenAddr
+	}
+	if subCfg.BasePath != "" {
+		cfg.BasePath = subCfg.BasePath
+	}
+	if subCfg.RemoteURL != "" {
+		cfg.RemoteDebuggingURL = subCfg.RemoteURL
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Errorf("Invalid config: %s", err)
+		return
+	}
 	runner := NewRunner(ctx, *cfg)
 	go func() {
 		c := make(chan os.Signal, 1)
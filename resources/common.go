@@ -0,0 +1,20 @@
+package resources
+
+import (
+	"context"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type ResourceHandler = func(rodCtx *types.Context) func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error)
+
+var (
+	CommonResources = []mcp.Resource{
+		Console,
+		Network,
+	}
+	CommonResourceHandlers = map[string]ResourceHandler{
+		"rod://console": ConsoleHandler,
+		"rod://network": NetworkHandler,
+	}
+)
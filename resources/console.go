@@ -1,9 +1,32 @@
 package resources
 
-import "github.com/mark3labs/mcp-go/mcp"
+import (
+	"context"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"strings"
+)
 
 var Console = mcp.NewResource(
 	"rod://console",
 	"Page console",
+	mcp.WithResourceDescription("Console messages and uncaught exceptions of the browser pages"),
 	mcp.WithMIMEType("text/plain"),
 )
+
+var ConsoleHandler = func(rodCtx *types.Context) func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		messages := rodCtx.ConsoleLogs().List(types.ConsoleFilter{})
+		lines := make([]string, 0, len(messages))
+		for _, m := range messages {
+			lines = append(lines, m.String())
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "text/plain",
+				Text:     strings.Join(lines, "\n"),
+			},
+		}, nil
+	}
+}
@@ -1,9 +1,32 @@
 package resources
 
-import "github.com/mark3labs/mcp-go/mcp"
+import (
+	"context"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"strings"
+)
 
 var Network = mcp.NewResource(
 	"rod://network",
 	"Page network",
+	mcp.WithResourceDescription("Network requests made by the browser pages and their responses"),
 	mcp.WithMIMEType("text/plain"),
 )
+
+var NetworkHandler = func(rodCtx *types.Context) func(context.Context, mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		requests := rodCtx.NetworkLogs().List(types.NetworkFilter{})
+		lines := make([]string, 0, len(requests))
+		for _, r := range requests {
+			lines = append(lines, r.String())
+		}
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      request.Params.URI,
+				MIMEType: "text/plain",
+				Text:     strings.Join(lines, "\n"),
+			},
+		}, nil
+	}
+}
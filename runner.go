@@ -3,22 +3,22 @@ package main
 import (
 	"context"
 	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod-mcp/server"
 	"github.com/go-rod/rod-mcp/types"
 )
 
 type Runner struct {
 	ctx    context.Context
 	rodCtx *types.Context
-	server *Server
+	server *server.Server
 }
 
 func NewRunner(ctx context.Context, cfg types.Config) *Runner {
 	rodCtx := types.NewContext(ctx, cfg)
-	server := NewServer(ctx, cfg)
 	return &Runner{
 		ctx:    ctx,
 		rodCtx: rodCtx,
-		server: server,
+		server: server.New(ctx, cfg),
 	}
 }
 
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"context"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod-mcp/resources"
+	"github.com/go-rod/rod-mcp/tools"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"github.com/pkg/errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Server serves the browser tools over the configured transport, each tool call runs through the
+// middlewares of the server
+type Server struct {
+	stdCtx      context.Context
+	cfg         types.Config
+	ctx         *types.Context
+	sessions    *sessionContexts
+	mcpServer   *mcpserver.MCPServer
+	metrics     *tools.Metrics
+	middlewares []tools.Middleware
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithMiddlewares adds middlewares around every tool call, they run inside the default recover, logging,
+// metrics, recovery report, keep alive, tab queue and timeout middlewares in the order they are given,
+// such as tools.Policy to reject the calls an embedder does not allow
+func WithMiddlewares(middlewares ...tools.Middleware) Option {
+	return func(s *Server) {
+		s.middlewares = append(s.middlewares, middlewares...)
+	}
+}
+
+// New creates the MCP server of the browser with the tools and resources registered
+func New(stdCtx context.Context, cfg types.Config, opts ...Option) *Server {
+	if cfg.RemoteDebuggingURL == "" {
+		if removed, err := types.CleanTempDirs(cfg); err != nil {
+			log.Warnf("Clean browser temp dirs error: %s", err)
+		} else if len(removed) > 0 {
+			log.Infof("Removed %d orphaned browser temp dirs", len(removed))
+		}
+	}
+	ctx := types.NewContext(stdCtx, cfg)
+	mcpServer := mcpserver.NewMCPServer(cfg.ServerName, cfg.ServerVersion)
+	metrics := tools.NewMetrics()
+	ser := &Server{
+		stdCtx:    stdCtx,
+		cfg:       cfg,
+		ctx:       ctx,
+		mcpServer: mcpServer,
+		metrics:   metrics,
+		// recover is the outermost so that the panics of the other middlewares are caught too
+		middlewares: []tools.Middleware{
+			tools.Recover(),
+			tools.Logging(),
+			metrics.Middleware(),
+			tools.ReportRecovery(),
+			tools.KeepAlive(),
+			tools.TabQueue(),
+			tools.Timeout(),
+		},
+	}
+	for _, opt := range opts {
+		opt(ser)
+	}
+	if cfg.Transport == types.TransportSSE || cfg.Transport == types.TransportStreamableHTTP {
+		// network clients must not share cookies, tabs or logs
+		ser.sessions = newSessionContexts(stdCtx, cfg)
+	}
+	ser.registerTools(tools.CommonTools...)
+	ser.registerResources(resources.CommonResources...)
+	return ser
+
+}
+
+// registerTools adds the tools with their handlers wrapped in the middlewares, the chain of a tool is
+// built once here rather than on every call
+func (s *Server) registerTools(mcpTools ...mcp.Tool) *Server {
+	for _, mt := range mcpTools {
+		if mt.Name == tools.Evaluate.Name && s.cfg.DisableEvaluate {
+			continue
+		}
+		if handlerFunc, ok := tools.CommonToolHandlers[mt.Name]; ok {
+			s.mcpServer.AddTool(mt, s.toolHandler(handlerFunc))
+		}
+
+	}
+	return s
+
+}
+
+// Metrics returns the call counters of the tools
+func (s *Server) Metrics() *tools.Metrics {
+	return s.metrics
+}
+
+func (s *Server) registerResources(mcpResources ...mcp.Resource) *Server {
+	for _, mr := range mcpResources {
+		if handlerFunc, ok := resources.CommonResourceHandlers[mr.URI]; ok {
+			s.mcpServer.AddResource(mr, s.resourceHandler(handlerFunc))
+		}
+	}
+	return s
+}
+
+// toolHandler binds the handler to the context of the client session calling the tool and runs it
+// through the middlewares, the errors of the tool are returned as results with isError set so that
+// the model can recover from them
+func (s *Server) toolHandler(handlerFunc tools.ToolHandler) mcpserver.ToolHandlerFunc {
+	call := tools.Chain(func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handlerFunc(rodCtx)(ctx, request)
+	}, s.middlewares...)
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		rodCtx := s.ctx
+		if s.sessions != nil {
+			var done func()
+			rodCtx, done = s.sessions.contextFor(ctx)
+			defer done()
+		}
+		result, err := call(ctx, rodCtx, request)
+		if err != nil {
+			return tools.ErrorResult(err), nil
+		}
+		return result, nil
+	}
+}
+
+// resourceHandler binds the handler to the context of the client session reading the resource
+func (s *Server) resourceHandler(handlerFunc resources.ResourceHandler) mcpserver.ResourceHandlerFunc {
+	if s.sessions == nil {
+		return handlerFunc(s.ctx)
+	}
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		rodCtx, done := s.sessions.contextFor(ctx)
+		defer done()
+		return handlerFunc(rodCtx)(ctx, request)
+	}
+}
+
+func (s *Server) Start() error {
+	switch s.cfg.Transport {
+	case types.TransportSSE:
+		sseServer := mcpserver.NewSSEServer(s.mcpServer, mcpserver.WithBasePath(s.cfg.BasePath))
+		return s.serveHTTP(endSSESessions(sseServer, s.sessions))
+	case types.TransportStreamableHTTP:
+		idleTimeout := s.cfg.SessionIdleTimeout
+		if idleTimeout <= 0 {
+			idleTimeout = types.DefaultSessionIdleTimeout
+		}
+		streamableServer := newStreamableHTTPServer(s.mcpServer, s.cfg.BasePath, idleTimeout)
+		streamableServer.onSessionEnd = s.sessions.end
+		go streamableServer.reapIdle(s.stdCtx)
+		return s.serveHTTP(streamableServer)
+	case types.TransportStdio, "":
+		if err := mcpserver.ServeStdio(s.mcpServer); err != nil {
+			return err
+		}
+		return nil
+	default:
+		return errors.Errorf("unsupported transport %s", s.cfg.Transport)
+	}
+}
+
+// serveHTTP serves the handler until the server context is done, then shuts the HTTP server down
+func (s *Server) serveHTTP(handler http.Handler) error {
+	addr := s.cfg.ListenAddr
+	if addr == "" {
+		addr = types.DefaultListenAddr
+	}
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: checkOrigin(handler, s.cfg.AllowedOrigins),
+		// requests inherit the server context so that long-lived event streams end on shutdown
+		BaseContext: func(net.Listener) context.Context { return s.stdCtx },
+	}
+	go func() {
+		<-s.stdCtx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Errorf("HTTP server shutdown error: %s", err)
+			_ = httpServer.Close()
+		}
+	}()
+	log.Infof("Serving %s transport on %s", s.cfg.Transport, addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) Close() error {
+	if s.sessions != nil {
+		if err := s.sessions.Close(); err != nil {
+			return err
+		}
+	}
+	return s.ctx.Close()
+}
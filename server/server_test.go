@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/go-rod/rod-mcp/tools"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"strings"
+	"testing"
+)
+
+func TestWithMiddlewares(t *testing.T) {
+	cfg := types.DefaultConfig
+	cfg.BrowserTempDir = t.TempDir()
+	policy := tools.Policy(func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) error {
+		if request.Params.Name == "denied" {
+			return errors.New("denied by the test policy")
+		}
+		return nil
+	})
+	broken := func(next tools.ToolCall) tools.ToolCall {
+		return func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if request.Params.Name == "broken" {
+				panic("broken middleware")
+			}
+			return next(ctx, rodCtx, request)
+		}
+	}
+	s := New(context.Background(), cfg, WithMiddlewares(policy, broken))
+	defer s.Close()
+
+	calls := 0
+	handler := s.toolHandler(func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			calls++
+			return mcp.NewToolResultText("done"), nil
+		}
+	})
+
+	tests := []struct {
+		name    string
+		isError bool
+		text    string
+		calls   int
+	}{
+		{name: "allowed", text: "done", calls: 1},
+		{name: "denied", isError: true, text: string(tools.CodeDisabled), calls: 0},
+		{name: "broken", isError: true, text: string(tools.CodeInternal), calls: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = 0
+			var request mcp.CallToolRequest
+			request.Params.Name = tt.name
+			result, err := handler(context.Background(), request)
+			if err != nil {
+				t.Fatalf("handler() error = %v", err)
+			}
+			if result.IsError != tt.isError {
+				t.Errorf("isError = %v, want %v", result.IsError, tt.isError)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if !strings.Contains(text, tt.text) {
+				t.Errorf("result = %s, want it to contain %s", text, tt.text)
+			}
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod-mcp/types"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// sessionIDRegexp finds the session id in the endpoint event of the SSE transport
+var sessionIDRegexp = regexp.MustCompile(`sessionId=([0-9a-fA-F-]+)`)
+
+type sessionContext struct {
+	ctx      *types.Context
+	lastUsed time.Time
+	// calls in progress, a session is not idle while it has calls
+	calls int
+}
+
+// sessionContexts gives every client session its own isolated types.Context on a shared browser,
+// the contexts are created on the first tool call and closed when the session ends or idles out
+type sessionContexts struct {
+	pool        *types.BrowserPool
+	idleTimeout time.Duration
+	contexts    map[string]*sessionContext
+	lock        sync.Mutex
+}
+
+func newSessionContexts(stdCtx context.Context, cfg types.Config) *sessionContexts {
+	idleTimeout := cfg.SessionIdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = types.DefaultSessionIdleTimeout
+	}
+	s := &sessionContexts{
+		pool:        types.NewBrowserPool(stdCtx, cfg),
+		idleTimeout: idleTimeout,
+		contexts:    map[string]*sessionContext{},
+	}
+	go s.reapIdle(stdCtx)
+	return s
+}
+
+// contextFor returns the context of the client session of the request, done must be called once the
+// call using the context is finished
+func (s *sessionContexts) contextFor(ctx context.Context) (rodCtx *types.Context, done func()) {
+	id := ""
+	if session := mcpserver.ClientSessionFromContext(ctx); session != nil {
+		id = session.SessionID()
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sc, ok := s.contexts[id]
+	if !ok {
+		log.Infof("Create browser context for session %s", id)
+		sc = &sessionContext{ctx: s.pool.NewContext()}
+		s.contexts[id] = sc
+	}
+	sc.lastUsed = time.Now()
+	sc.calls++
+	var once sync.Once
+	return sc.ctx, func() {
+		once.Do(func() {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			sc.lastUsed = time.Now()
+			sc.calls--
+		})
+	}
+}
+
+// end closes the context of the session, it is a no-op for a session without context
+func (s *sessionContexts) end(id string) {
+	s.lock.Lock()
+	sc, ok := s.contexts[id]
+	delete(s.contexts, id)
+	s.lock.Unlock()
+	if ok {
+		s.close(id, sc)
+	}
+}
+
+func (s *sessionContexts) close(id string, sc *sessionContext) {
+	log.Infof("Close browser context of session %s", id)
+	if err := sc.ctx.Close(); err != nil {
+		log.Errorf("Close browser context of session %s error: %s", id, err)
+	}
+}
+
+func (s *sessionContexts) reapIdle(stdCtx context.Context) {
+	ticker := time.NewTicker(s.idleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stdCtx.Done():
+			return
+		case now := <-ticker.C:
+			// the idle sessions are removed in the same locked section they are found in, so that
+			// a call starting meanwhile gets a new context rather than one being closed
+			idle := map[string]*sessionContext{}
+			s.lock.Lock()
+			for id, sc := range s.contexts {
+				if sc.calls == 0 && now.Sub(sc.lastUsed) > s.idleTimeout {
+					idle[id] = sc
+					delete(s.contexts, id)
+				}
+			}
+			s.lock.Unlock()
+			for id, sc := range idle {
+				log.Infof("Session %s idle for %s", id, s.idleTimeout)
+				s.close(id, sc)
+			}
+		}
+	}
+}
+
+// Close all the session contexts and the shared browser
+func (s *sessionContexts) Close() error {
+	s.lock.Lock()
+	ids := make([]string, 0, len(s.contexts))
+	for id := range s.contexts {
+		ids = append(ids, id)
+	}
+	s.lock.Unlock()
+	for _, id := range ids {
+		s.end(id)
+	}
+	return s.pool.Close()
+}
+
+// endSSESessions ends the session context when the event stream of a SSE client disconnects,
+// the session id is only known from the endpoint event written to the stream
+func endSSESessions(next http.Handler, sessions *sessionContexts) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		sw := &sessionSniffer{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+		if sw.sessionID != "" {
+			sessions.end(sw.sessionID)
+		}
+	})
+}
+
+type sessionSniffer struct {
+	http.ResponseWriter
+	sessionID string
+}
+
+func (w *sessionSniffer) Write(b []byte) (int, error) {
+	if w.sessionID == "" {
+		if m := sessionIDRegexp.FindSubmatch(b); m != nil {
+			w.sessionID = string(m[1])
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *sessionSniffer) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,297 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	sessionIDHeader       = "Mcp-Session-Id"
+	streamableHTTPPath    = "/mcp"
+	notificationQueueSize = 100
+)
+
+// streamableSession is a client session of the streamable HTTP transport
+type streamableSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+	// lastSeen is the unix nano time of the last request of the session
+	lastSeen atomic.Int64
+	// streams counts the open event streams, a session with a stream is not idle
+	streams atomic.Int32
+}
+
+func (s *streamableSession) touch() {
+	s.lastSeen.Store(time.Now().UnixNano())
+}
+
+func (s *streamableSession) SessionID() string {
+	return s.id
+}
+
+func (s *streamableSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// streamableHTTPServer implements the streamable HTTP transport of MCP on a single endpoint:
+// POST sends JSON-RPC messages, GET opens an event stream for server notifications and
+// DELETE terminates the session
+type streamableHTTPServer struct {
+	mcpServer    *mcpserver.MCPServer
+	path         string
+	idleTimeout  time.Duration
+	sessions     sync.Map
+	onSessionEnd func(id string)
+}
+
+// newStreamableHTTPServer creates the transport, the sessions which are neither used nor streaming for
+// idleTimeout are ended by reapIdle as clients do not always delete their session
+func newStreamableHTTPServer(mcpServer *mcpserver.MCPServer, basePath string, idleTimeout time.Duration) *streamableHTTPServer {
+	return &streamableHTTPServer{
+		mcpServer:   mcpServer,
+		path:        strings.TrimSuffix(basePath, "/") + streamableHTTPPath,
+		idleTimeout: idleTimeout,
+	}
+}
+
+func (s *streamableHTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != s.path {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodPost:
+		s.handlePost(w, r)
+	case http.MethodGet:
+		s.handleGet(w, r)
+	case http.MethodDelete:
+		s.handleDelete(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *streamableHTTPServer) handlePost(w http.ResponseWriter, r *http.Request) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		writeJSONRPCError(w, http.StatusBadRequest, nil, mcp.PARSE_ERROR, "Parse error")
+		return
+	}
+	batch := strings.HasPrefix(strings.TrimSpace(string(raw)), "[")
+	messages := []json.RawMessage{raw}
+	if batch {
+		if err := json.Unmarshal(raw, &messages); err != nil {
+			writeJSONRPCError(w, http.StatusBadRequest, nil, mcp.PARSE_ERROR, "Parse error")
+			return
+		}
+	}
+
+	session, status, err := s.sessionFor(r, messages)
+	if err != nil {
+		writeJSONRPCError(w, status, nil, mcp.INVALID_REQUEST, err.Error())
+		return
+	}
+	session.touch()
+	ctx := s.mcpServer.WithContext(r.Context(), session)
+
+	responses := make([]mcp.JSONRPCMessage, 0, len(messages))
+	for _, message := range messages {
+		if response := s.mcpServer.HandleMessage(ctx, message); response != nil {
+			responses = append(responses, response)
+		}
+	}
+
+	w.Header().Set(sessionIDHeader, session.id)
+	if len(responses) == 0 {
+		// notifications and responses from the client have no reply
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if batch {
+		_ = json.NewEncoder(w).Encode(responses)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(responses[0])
+}
+
+// sessionFor returns the session of the request, an initialize request starts a new session. A request
+// without session id is a bad request while an unknown session id is not found, the client starts a
+// new session on not found
+func (s *streamableHTTPServer) sessionFor(r *http.Request, messages []json.RawMessage) (*streamableSession, int, error) {
+	if id := r.Header.Get(sessionIDHeader); id != "" {
+		if session, ok := s.sessions.Load(id); ok {
+			return session.(*streamableSession), http.StatusOK, nil
+		}
+		return nil, http.StatusNotFound, fmt.Errorf("session %s not found", id)
+	}
+	for _, message := range messages {
+		var base struct {
+			Method mcp.MCPMethod `json:"method"`
+		}
+		if json.Unmarshal(message, &base) == nil && base.Method == mcp.MethodInitialize {
+			session := &streamableSession{
+				id:            uuid.New().String(),
+				notifications: make(chan mcp.JSONRPCNotification, notificationQueueSize),
+			}
+			if err := s.mcpServer.RegisterSession(session); err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+			s.sessions.Store(session.id, session)
+			return session, http.StatusOK, nil
+		}
+	}
+	return nil, http.StatusBadRequest, fmt.Errorf("missing %s header", sessionIDHeader)
+}
+
+// requestSession returns the session of a GET or DELETE request, it writes the error response when the
+// session is missing
+func (s *streamableHTTPServer) requestSession(w http.ResponseWriter, r *http.Request) (*streamableSession, bool) {
+	id := r.Header.Get(sessionIDHeader)
+	if id == "" {
+		http.Error(w, fmt.Sprintf("Missing %s header", sessionIDHeader), http.StatusBadRequest)
+		return nil, false
+	}
+	value, ok := s.sessions.Load(id)
+	if !ok {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return nil, false
+	}
+	return value.(*streamableSession), true
+}
+
+// handleGet streams the server notifications of the session as server-sent events
+func (s *streamableHTTPServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.requestSession(w, r)
+	if !ok {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	session.streams.Add(1)
+	defer func() {
+		session.touch()
+		session.streams.Add(-1)
+	}()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set(sessionIDHeader, session.id)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case notification := <-session.notifications:
+			data, err := json.Marshal(notification)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *streamableHTTPServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	session, ok := s.requestSession(w, r)
+	if !ok {
+		return
+	}
+	if !s.endSession(session.id) {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	log.Infof("Session %s terminated", session.id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// endSession forgets the session and releases its browser context, it reports false when the session
+// was already ended
+func (s *streamableHTTPServer) endSession(id string) bool {
+	if _, ok := s.sessions.LoadAndDelete(id); !ok {
+		return false
+	}
+	s.mcpServer.UnregisterSession(id)
+	if s.onSessionEnd != nil {
+		s.onSessionEnd(id)
+	}
+	return true
+}
+
+// reapIdle ends the sessions idle for idleTimeout until ctx is done
+func (s *streamableHTTPServer) reapIdle(ctx context.Context) {
+	ticker := time.NewTicker(s.idleTimeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sessions.Range(func(key, value interface{}) bool {
+				session := value.(*streamableSession)
+				idle := now.Sub(time.Unix(0, session.lastSeen.Load()))
+				if session.streams.Load() == 0 && idle > s.idleTimeout && s.endSession(session.id) {
+					log.Infof("Session %s expired after %s idle", session.id, s.idleTimeout)
+				}
+				return true
+			})
+		}
+	}
+}
+
+// checkOrigin rejects the browser requests from other origins than the allowed ones to prevent DNS
+// rebinding attacks on the local server, the loopback origins are always allowed and requests without
+// Origin header, which do not come from browsers, pass
+func checkOrigin(next http.Handler, allowedOrigins []string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && !originAllowed(origin, allowedOrigins) {
+			log.Warnf("Rejected request from origin %s", origin)
+			http.Error(w, "Origin not allowed", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func originAllowed(origin string, allowedOrigins []string) bool {
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func writeJSONRPCError(w http.ResponseWriter, status int, id interface{}, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(mcp.NewJSONRPCError(id, code, message, nil))
+}
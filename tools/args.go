@@ -0,0 +1,359 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mark3labs/mcp-go/mcp"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The arguments of a tool are declared as a struct, the tags of a field describe its argument:
+//
+//	json        name of the argument
+//	description description shown to the model
+//	required    "true" when the argument must be set, an empty string counts as not set
+//	enum        comma separated allowed values
+//	min, max    range of a number
+//	default     value used when the argument is not set
+//
+// newTool generates the input schema from the struct and decodeArgs decodes and validates the
+// arguments of a call into it, so the definition of a tool and its handler cannot drift apart
+
+// newTool creates the tool with the input schema generated from the args struct
+func newTool(name, description string, args interface{}) mcp.Tool {
+	tool := mcp.NewTool(name, mcp.WithDescription(description))
+	tool.InputSchema.Properties, tool.InputSchema.Required = objectSchema(reflect.TypeOf(args))
+	return tool
+}
+
+// objectSchema describes the fields of the struct as the properties of a JSON object
+func objectSchema(t reflect.Type) (properties map[string]interface{}, required []string) {
+	properties = map[string]interface{}{}
+	for _, f := range argFields(t) {
+		properties[f.name] = f.schema()
+		if f.required {
+			required = append(required, f.name)
+		}
+	}
+	return properties, required
+}
+
+func (f argField) schema() map[string]interface{} {
+	prop := typeSchema(f.typ)
+	if len(f.enum) > 0 {
+		if items, ok := prop["items"].(map[string]interface{}); ok {
+			items["enum"] = f.enum
+		} else {
+			prop["enum"] = f.enum
+		}
+	}
+	if f.description != "" {
+		prop["description"] = f.description
+	}
+	if f.min != nil {
+		prop["minimum"] = *f.min
+	}
+	if f.max != nil {
+		prop["maximum"] = *f.max
+	}
+	if f.hasDefault {
+		prop["default"] = f.defaultValue
+	}
+	return prop
+}
+
+// typeSchema describes the type, the items of slices and the fields of structs included
+func typeSchema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	prop := map[string]interface{}{}
+	if typ := schemaType(t); typ != "" {
+		prop["type"] = typ
+	}
+	switch t.Kind() {
+	case reflect.Slice:
+		if items := typeSchema(t.Elem()); len(items) > 0 {
+			prop["items"] = items
+		}
+	case reflect.Struct:
+		properties, required := objectSchema(t)
+		prop["properties"] = properties
+		if len(required) > 0 {
+			prop["required"] = required
+		}
+	}
+	return prop
+}
+
+// decodeArgs decodes the arguments of the call into the args struct pointed to by out, the missing
+// arguments get their default and the values are checked against the tags of the fields, the objects
+// nested in the arguments are checked against the tags of their struct as well
+func decodeArgs(request mcp.CallToolRequest, out interface{}) error {
+	t := reflect.TypeOf(out).Elem()
+	values, err := prepareObject(t, request.Params.Arguments, "")
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return invalidArgument("invalid arguments: %s", err.Error())
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return invalidArgument("%s must be %s, got %s", typeErr.Field, typeDescription(typeErr.Type), typeErr.Value)
+		}
+		return invalidArgument("invalid arguments: %s", err.Error())
+	}
+	return validateObject(reflect.ValueOf(out).Elem(), values, "")
+}
+
+// prepareObject returns the arguments of the struct type with the defaults of the missing ones,
+// a missing required argument is an error
+func prepareObject(t reflect.Type, args map[string]interface{}, prefix string) (map[string]interface{}, error) {
+	fields := argFields(t)
+	values := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		name := prefix + f.name
+		v, ok := args[f.name]
+		if str, isStr := v.(string); !ok || v == nil || (isStr && str == "" && f.typ.Kind() != reflect.String) {
+			// clients send empty strings for unset optional arguments of any type
+			ok = false
+		}
+		if !ok || (f.required && v == "") {
+			if f.required {
+				return nil, invalidArgument("%s is required", name)
+			}
+			if f.hasDefault {
+				values[f.name] = f.defaultValue
+			}
+			continue
+		}
+		v, err := prepareNested(f.typ, v, name)
+		if err != nil {
+			return nil, err
+		}
+		values[f.name] = v
+	}
+	return values, nil
+}
+
+// prepareNested prepares the objects of a struct or a slice of structs argument, other values and
+// values of the wrong type are returned as they are and left to the decoding
+func prepareNested(t reflect.Type, v interface{}, name string) (interface{}, error) {
+	t = elemType(t)
+	switch {
+	case t.Kind() == reflect.Struct:
+		if obj, ok := v.(map[string]interface{}); ok {
+			return prepareObject(t, obj, name+".")
+		}
+	case t.Kind() == reflect.Slice && elemType(t.Elem()).Kind() == reflect.Struct:
+		if items, ok := v.([]interface{}); ok {
+			prepared := make([]interface{}, len(items))
+			for i, item := range items {
+				if item == nil {
+					return nil, invalidArgument("%s[%d] must be an object", name, i)
+				}
+				p, err := prepareNested(t.Elem(), item, fmt.Sprintf("%s[%d]", name, i))
+				if err != nil {
+					return nil, err
+				}
+				prepared[i] = p
+			}
+			return prepared, nil
+		}
+	}
+	return v, nil
+}
+
+// validateObject checks the decoded fields of the struct which were set in values
+func validateObject(v reflect.Value, values map[string]interface{}, prefix string) error {
+	for _, f := range argFields(v.Type()) {
+		value, set := values[f.name]
+		if !set {
+			continue
+		}
+		if err := f.validate(v.Field(f.index), value, prefix+f.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type argField struct {
+	index        int
+	name         string
+	typ          reflect.Type
+	description  string
+	required     bool
+	enum         []string
+	min, max     *float64
+	hasDefault   bool
+	defaultValue interface{}
+}
+
+func argFields(t reflect.Type) []argField {
+	fields := make([]argField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		f := argField{
+			index:       i,
+			name:        name,
+			typ:         sf.Type,
+			description: sf.Tag.Get("description"),
+			required:    sf.Tag.Get("required") == "true",
+		}
+		if enum := sf.Tag.Get("enum"); enum != "" {
+			f.enum = strings.Split(enum, ",")
+		}
+		f.min = parseBound(sf.Tag.Get("min"))
+		f.max = parseBound(sf.Tag.Get("max"))
+		if def, ok := sf.Tag.Lookup("default"); ok {
+			f.hasDefault = true
+			f.defaultValue = parseDefault(sf.Type, def)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func (f argField) validate(v reflect.Value, value interface{}, name string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if len(f.enum) > 0 && !containsString(f.enum, v.String()) {
+			return invalidArgument("%s must be one of %s, got %q", name, strings.Join(f.enum, ", "), v.String())
+		}
+	case reflect.Slice:
+		if len(f.enum) > 0 && v.Type().Elem().Kind() == reflect.String {
+			for i := 0; i < v.Len(); i++ {
+				if !containsString(f.enum, v.Index(i).String()) {
+					return invalidArgument("%s must only contain %s, got %q", name, strings.Join(f.enum, ", "), v.Index(i).String())
+				}
+			}
+		}
+		items, _ := value.([]interface{})
+		for i := 0; i < v.Len() && i < len(items); i++ {
+			item, _ := items[i].(map[string]interface{})
+			elem := v.Index(i)
+			if elem.Kind() == reflect.Ptr {
+				if elem.IsNil() {
+					continue
+				}
+				elem = elem.Elem()
+			}
+			if elem.Kind() != reflect.Struct {
+				break
+			}
+			if err := validateObject(elem, item, fmt.Sprintf("%s[%d].", name, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		obj, _ := value.(map[string]interface{})
+		return validateObject(v, obj, name+".")
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		n := v.Convert(reflect.TypeOf(float64(0))).Float()
+		if f.min != nil && n < *f.min {
+			return invalidArgument("%s must be greater than or equal to %v, got %v", name, *f.min, n)
+		}
+		if f.max != nil && n > *f.max {
+			return invalidArgument("%s must be less than or equal to %v, got %v", name, *f.max, n)
+		}
+	}
+	return nil
+}
+
+func elemType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+func schemaType(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Float64:
+		return "number"
+	case reflect.Slice:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		// interface{} accepts any JSON value
+		return ""
+	}
+}
+
+func typeDescription(t reflect.Type) string {
+	switch typ := schemaType(t); typ {
+	case "array", "integer", "object":
+		return "an " + typ
+	case "":
+		return "a JSON value"
+	default:
+		return "a " + typ
+	}
+}
+
+func parseBound(s string) *float64 {
+	if s == "" {
+		return nil
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		panic("invalid bound " + s)
+	}
+	return &n
+}
+
+// parseDefault converts the default tag to the JSON value of the field type
+func parseDefault(t reflect.Type, s string) interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return s == "true"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			panic("invalid default " + s)
+		}
+		return n
+	default:
+		return s
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
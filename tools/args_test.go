@@ -0,0 +1,199 @@
+package tools
+
+import (
+	"errors"
+	"github.com/mark3labs/mcp-go/mcp"
+	"reflect"
+	"testing"
+)
+
+type testPoint struct {
+	X float64 `json:"x" required:"true" min:"0"`
+	Y float64 `json:"y" required:"true" max:"100"`
+}
+
+type testArgs struct {
+	Name   string      `json:"name" required:"true"`
+	Mode   string      `json:"mode" enum:"fast,slow" default:"fast"`
+	Count  int         `json:"count" min:"1" max:"10" default:"3"`
+	Flag   bool        `json:"flag" default:"true"`
+	Kinds  []string    `json:"kinds" enum:"a,b"`
+	Limit  *int        `json:"limit" min:"0"`
+	Origin *testPoint  `json:"origin"`
+	Path   []testPoint `json:"path"`
+}
+
+func callRequest(args map[string]interface{}) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = args
+	return request
+}
+
+func TestDecodeArgs(t *testing.T) {
+	limit := 5
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want testArgs
+	}{
+		{
+			name: "defaults",
+			args: map[string]interface{}{"name": "a"},
+			want: testArgs{Name: "a", Mode: "fast", Count: 3, Flag: true},
+		},
+		{
+			name: "set values",
+			args: map[string]interface{}{"name": "a", "mode": "slow", "count": float64(10), "flag": false, "kinds": []interface{}{"b"}},
+			want: testArgs{Name: "a", Mode: "slow", Count: 10, Kinds: []string{"b"}},
+		},
+		{
+			name: "empty strings are unset",
+			args: map[string]interface{}{"name": "a", "count": "", "flag": "", "limit": ""},
+			want: testArgs{Name: "a", Mode: "fast", Count: 3, Flag: true},
+		},
+		{
+			name: "null is unset",
+			args: map[string]interface{}{"name": "a", "mode": nil},
+			want: testArgs{Name: "a", Mode: "fast", Count: 3, Flag: true},
+		},
+		{
+			name: "pointer",
+			args: map[string]interface{}{"name": "a", "limit": float64(5)},
+			want: testArgs{Name: "a", Mode: "fast", Count: 3, Flag: true, Limit: &limit},
+		},
+		{
+			name: "nested objects",
+			args: map[string]interface{}{
+				"name":   "a",
+				"origin": map[string]interface{}{"x": float64(1), "y": float64(2)},
+				"path":   []interface{}{map[string]interface{}{"x": float64(3), "y": float64(4)}},
+			},
+			want: testArgs{Name: "a", Mode: "fast", Count: 3, Flag: true, Origin: &testPoint{X: 1, Y: 2}, Path: []testPoint{{X: 3, Y: 4}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testArgs
+			if err := decodeArgs(callRequest(tt.args), &got); err != nil {
+				t.Fatalf("decodeArgs() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeArgs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeArgsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{
+			name: "missing required",
+			args: map[string]interface{}{},
+			want: "name is required",
+		},
+		{
+			name: "empty required string",
+			args: map[string]interface{}{"name": ""},
+			want: "name is required",
+		},
+		{
+			name: "enum",
+			args: map[string]interface{}{"name": "a", "mode": "medium"},
+			want: `mode must be one of fast, slow, got "medium"`,
+		},
+		{
+			name: "enum of slice",
+			args: map[string]interface{}{"name": "a", "kinds": []interface{}{"a", "c"}},
+			want: `kinds must only contain a, b, got "c"`,
+		},
+		{
+			name: "below min",
+			args: map[string]interface{}{"name": "a", "count": float64(0)},
+			want: "count must be greater than or equal to 1, got 0",
+		},
+		{
+			name: "above max",
+			args: map[string]interface{}{"name": "a", "count": float64(11)},
+			want: "count must be less than or equal to 10, got 11",
+		},
+		{
+			name: "pointer below min",
+			args: map[string]interface{}{"name": "a", "limit": float64(-1)},
+			want: "limit must be greater than or equal to 0, got -1",
+		},
+		{
+			name: "wrong type",
+			args: map[string]interface{}{"name": "a", "count": "many"},
+			want: "count must be an integer, got string",
+		},
+		{
+			name: "nested required",
+			args: map[string]interface{}{"name": "a", "origin": map[string]interface{}{"x": float64(1)}},
+			want: "origin.y is required",
+		},
+		{
+			name: "nested range",
+			args: map[string]interface{}{"name": "a", "origin": map[string]interface{}{"x": float64(-1), "y": float64(0)}},
+			want: "origin.x must be greater than or equal to 0, got -1",
+		},
+		{
+			name: "item required",
+			args: map[string]interface{}{"name": "a", "path": []interface{}{map[string]interface{}{"x": float64(1), "y": float64(1)}, map[string]interface{}{"x": float64(1)}}},
+			want: "path[1].y is required",
+		},
+		{
+			name: "item range",
+			args: map[string]interface{}{"name": "a", "path": []interface{}{map[string]interface{}{"x": float64(1), "y": float64(101)}}},
+			want: "path[0].y must be less than or equal to 100, got 101",
+		},
+		{
+			name: "null item",
+			args: map[string]interface{}{"name": "a", "path": []interface{}{nil}},
+			want: "path[0] must be an object",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testArgs
+			err := decodeArgs(callRequest(tt.args), &got)
+			var toolErr *ToolError
+			if !errors.As(err, &toolErr) {
+				t.Fatalf("decodeArgs() error = %v, want a ToolError", err)
+			}
+			if toolErr.Code != CodeInvalidArgument {
+				t.Errorf("decodeArgs() code = %s, want %s", toolErr.Code, CodeInvalidArgument)
+			}
+			if toolErr.Message != tt.want {
+				t.Errorf("decodeArgs() message = %q, want %q", toolErr.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewToolSchema(t *testing.T) {
+	tool := newTool("test", "test tool", testArgs{})
+	if !reflect.DeepEqual(tool.InputSchema.Required, []string{"name"}) {
+		t.Errorf("required = %v, want [name]", tool.InputSchema.Required)
+	}
+	mode := tool.InputSchema.Properties["mode"].(map[string]interface{})
+	if mode["type"] != "string" || mode["default"] != "fast" || !reflect.DeepEqual(mode["enum"], []string{"fast", "slow"}) {
+		t.Errorf("mode schema = %v", mode)
+	}
+	count := tool.InputSchema.Properties["count"].(map[string]interface{})
+	if count["type"] != "integer" || count["minimum"] != float64(1) || count["maximum"] != float64(10) {
+		t.Errorf("count schema = %v", count)
+	}
+	kinds := tool.InputSchema.Properties["kinds"].(map[string]interface{})
+	if items := kinds["items"].(map[string]interface{}); !reflect.DeepEqual(items["enum"], []string{"a", "b"}) {
+		t.Errorf("kinds schema = %v", kinds)
+	}
+	path := tool.InputSchema.Properties["path"].(map[string]interface{})
+	items := path["items"].(map[string]interface{})
+	if !reflect.DeepEqual(items["required"], []string{"x", "y"}) {
+		t.Errorf("path schema = %v", path)
+	}
+}
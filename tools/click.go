@@ -0,0 +1,214 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/mark3labs/mcp-go/mcp"
+	"strings"
+)
+
+type clickArgs struct {
+	Selector   string   `json:"selector" description:"CSS selector of the element to click"`
+	Ref        string   `json:"ref" description:"Ref of the element to click from rod_snapshot, used instead of selector"`
+	Button     string   `json:"button" enum:"left,right,middle" default:"left" description:"Mouse button to click with, 'right' opens the context menu"`
+	ClickCount int      `json:"click_count" min:"1" max:"3" default:"1" description:"Number of clicks, 2 for a double click"`
+	Modifiers  []string `json:"modifiers" enum:"Alt,Control,Meta,Shift" description:"Modifier keys held down during the click"`
+	OffsetX    *float64 `json:"offset_x" description:"Horizontal position to click at in pixels from the left edge of the element, the center if not set"`
+	OffsetY    *float64 `json:"offset_y" description:"Vertical position to click at in pixels from the top edge of the element, the center if not set"`
+	Force      bool     `json:"force" description:"Click even if the element is covered, hidden from the pointer or disabled"`
+}
+
+type hoverArgs struct {
+	Selector  string   `json:"selector" description:"CSS selector of the element to hover"`
+	Ref       string   `json:"ref" description:"Ref of the element to hover from rod_snapshot, used instead of selector"`
+	Modifiers []string `json:"modifiers" enum:"Alt,Control,Meta,Shift" description:"Modifier keys held down while hovering"`
+	OffsetX   *float64 `json:"offset_x" description:"Horizontal position to hover at in pixels from the left edge of the element, the center if not set"`
+	OffsetY   *float64 `json:"offset_y" description:"Vertical position to hover at in pixels from the top edge of the element, the center if not set"`
+	Force     bool     `json:"force" description:"Hover even if the element is covered or hidden from the pointer"`
+}
+
+var (
+	Click = newTool("rod_click", "Click an element on the page, supports double clicks, the right and middle buttons, modifier keys and clicking at an offset within the element", clickArgs{})
+	Hover = newTool("rod_hover", "Move the mouse over an element on the page, such as to reveal a menu or a tooltip", hoverArgs{})
+)
+
+var (
+	ClickHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args clickArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			page, timeout, err := activePage(ctx, rodCtx, request)
+			if err != nil {
+				log.Errorf("Failed to click element: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to click element")
+			}
+			defer page.CancelTimeout()
+			element, selector, err := findElement(rodCtx, page, elementTarget{Selector: args.Selector, Ref: args.Ref})
+			if err != nil {
+				log.Errorf("Failed to find element %s: %s", selector, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to find element %s", selector)
+			}
+			point, err := pointerTarget(element, args.OffsetX, args.OffsetY, args.Force)
+			if err != nil {
+				log.Errorf("Failed to click element %s: %s", selector, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to click element %s", selector)
+			}
+			if !args.Force {
+				if err = element.WaitEnabled(); err != nil {
+					log.Errorf("Failed to click element %s: %s", selector, reason(err, timeout))
+					return nil, fail(err, timeout, "Failed to click element %s", selector)
+				}
+			}
+			pointer, err := tabPointer(rodCtx, page)
+			if err == nil {
+				err = withModifiers(page, pointer, args.Modifiers, func() error {
+					if err := pointer.MoveTo(page, point); err != nil {
+						return err
+					}
+					return pointer.Click(page, proto.InputMouseButton(args.Button), args.ClickCount)
+				})
+			}
+			if err != nil {
+				log.Errorf("Failed to click element %s: %s", selector, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to click element %s", selector)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("%s element %s successfully", describeClick(args), selector)), nil
+		}
+	}
+
+	HoverHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args hoverArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			page, timeout, err := activePage(ctx, rodCtx, request)
+			if err != nil {
+				log.Errorf("Failed to hover element: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to hover element")
+			}
+			defer page.CancelTimeout()
+			element, selector, err := findElement(rodCtx, page, elementTarget{Selector: args.Selector, Ref: args.Ref})
+			if err != nil {
+				log.Errorf("Failed to find element %s: %s", selector, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to find element %s", selector)
+			}
+			point, err := pointerTarget(element, args.OffsetX, args.OffsetY, args.Force)
+			var pointer *types.Pointer
+			if err == nil {
+				pointer, err = tabPointer(rodCtx, page)
+			}
+			if err == nil {
+				err = withModifiers(page, pointer, args.Modifiers, func() error {
+					return pointer.MoveTo(page, point)
+				})
+			}
+			if err != nil {
+				log.Errorf("Failed to hover element %s: %s", selector, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to hover element %s", selector)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Hover element %s successfully", selector)), nil
+		}
+	}
+)
+
+// pointerTarget scrolls the element into view and returns the point of the element the mouse acts on,
+// the offset is relative to the top left corner of the element and the center is used without it.
+// Unless forced, it waits until the element can receive the pointer at its center
+func pointerTarget(element *rod.Element, offsetX, offsetY *float64, force bool) (proto.Point, error) {
+	var center *proto.Point
+	if force {
+		if err := element.ScrollIntoView(); err != nil {
+			return proto.Point{}, err
+		}
+	} else {
+		pt, err := element.WaitInteractable()
+		if err != nil {
+			return proto.Point{}, err
+		}
+		center = pt
+	}
+	if center != nil && offsetX == nil && offsetY == nil {
+		return *center, nil
+	}
+	shape, err := element.Shape()
+	if err != nil {
+		return proto.Point{}, err
+	}
+	box := shape.Box()
+	if box == nil {
+		return proto.Point{}, &rod.InvisibleShapeError{Element: element}
+	}
+	point := proto.NewPoint(box.X+box.Width/2, box.Y+box.Height/2)
+	if offsetX != nil {
+		point.X = box.X + *offsetX
+	}
+	if offsetY != nil {
+		point.Y = box.Y + *offsetY
+	}
+	return point, nil
+}
+
+// tabPointer returns the mouse and keyboard of the tab of the page
+func tabPointer(rodCtx *types.Context, page *rod.Page) (*types.Pointer, error) {
+	tab := rodCtx.TabOf(page)
+	if tab == nil {
+		return nil, newToolError(CodeBrowserClosed, "the tab was closed")
+	}
+	return tab.Pointer, nil
+}
+
+// withModifiers holds the modifier keys down while the action runs, the keys are released with a
+// timeout of their own so that they are not left held when the call timed out or was cancelled
+func withModifiers(page *rod.Page, pointer *types.Pointer, modifiers []string, action func() error) error {
+	pressed := make([]input.Key, 0, len(modifiers))
+	defer func() {
+		if len(pressed) == 0 {
+			return
+		}
+		page := restoringPage(page)
+		defer page.CancelTimeout()
+		for i := len(pressed) - 1; i >= 0; i-- {
+			_ = pointer.Release(page, pressed[i])
+		}
+	}()
+	for _, modifier := range modifiers {
+		key, ok := namedKeys[modifier]
+		if !ok {
+			return invalidArgument("Unsupported modifier %s", modifier)
+		}
+		// a key which failed to be pressed is released too, the key down may have reached the page
+		pressed = append(pressed, key)
+		if err := pointer.Press(page, key); err != nil {
+			return err
+		}
+	}
+	return action()
+}
+
+// describeClick names the click for the result message, such as `Right double click`
+func describeClick(args clickArgs) string {
+	parts := make([]string, 0, 4)
+	if len(args.Modifiers) > 0 {
+		parts = append(parts, strings.Join(args.Modifiers, "+"))
+	}
+	if args.Button != string(proto.InputMouseButtonLeft) {
+		parts = append(parts, args.Button)
+	}
+	switch args.ClickCount {
+	case 2:
+		parts = append(parts, "double")
+	case 3:
+		parts = append(parts, "triple")
+	}
+	parts = append(parts, "click")
+	description := strings.Join(parts, " ")
+	return strings.ToUpper(description[:1]) + description[1:]
+}
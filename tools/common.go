@@ -2,13 +2,9 @@ package tools
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/charmbracelet/log"
 	"github.com/go-rod/rod-mcp/types"
-	"github.com/go-rod/rod-mcp/utils"
-	"github.com/go-rod/rod/lib/input"
-	"github.com/go-rod/rod/lib/proto"
 	"github.com/mark3labs/mcp-go/mcp"
 	"time"
 )
@@ -18,203 +14,154 @@ const (
 	defaultDomDiff       = 0.2
 )
 
+type pressKeyArgs struct {
+	Key string `json:"key" required:"true" description:"Name of the key to press or a character to generate, such as 'ArrowLeft' or 'a'"`
+}
+
+type fillArgs struct {
+	Selector string `json:"selector" description:"CSS selector of the element to type into"`
+	Ref      string `json:"ref" description:"Ref of the element to type into from rod_snapshot, used instead of selector"`
+	Value    string `json:"value" required:"true" description:"Value to fill"`
+}
+
 var (
-	Navigation = mcp.NewTool("rod_navigate",
-		mcp.WithDescription("Navigate to a URL"),
-		mcp.WithString("url", mcp.Description("URL to navigate to"), mcp.Required()),
-	)
-	GoBack = mcp.NewTool("rod_go_back",
-		mcp.WithDescription("Go back in the browser history, go back to the previous page"),
-	)
-	GoForward = mcp.NewTool("rod_go_forward",
-		mcp.WithDescription("Go forward in the browser history, go to the next page"),
-	)
-	ReLoad = mcp.NewTool("rod_reload",
-		mcp.WithDescription("Reload the current page"),
-	)
-	PressKey = mcp.NewTool("rod_press_key",
-		mcp.WithDescription("Press a key on the keyboard"),
-		mcp.WithString("key", mcp.Description("Name of the key to press or a character to generate, such as `ArrowLeft` or `a`"), mcp.Required()),
-	)
-	Pdf = mcp.NewTool("rod_pdf",
-		mcp.WithDescription("Generate a PDF from the current page"),
-		mcp.WithString("file_path", mcp.Description("Path to save the PDF file"), mcp.Required()),
-		mcp.WithString("file_name", mcp.Description("Name of the PDF file"), mcp.Required()),
-	)
-	CloseBrowser = mcp.NewTool("rod_close_browser",
-		mcp.WithDescription("Close the browser"),
-	)
-	Screenshot = mcp.NewTool("rod_screenshot",
-		mcp.WithDescription("Take a screenshot of the current page or a specific element"),
-		mcp.WithString("name", mcp.Description("Name of the screenshot"), mcp.Required()),
-		mcp.WithString("selector", mcp.Description("CSS selector of the element to take a screenshot of")),
-		mcp.WithNumber("width", mcp.Description("Width in pixels (default: 800)")),
-		mcp.WithNumber("height", mcp.Description("Height in pixels (default: 600)")),
-	)
-	Click = mcp.NewTool("rod_click",
-		mcp.WithDescription("Click an element on the page"),
-		mcp.WithString("selector", mcp.Description("CSS selector of the element to click"), mcp.Required()),
-	)
-	Fill = mcp.NewTool("rod_fill",
-		mcp.WithDescription("Fill out an input field"),
-		mcp.WithString("selector", mcp.Description("CSS selector of the element to type into"), mcp.Required()),
-		mcp.WithString("value", mcp.Description("Value to fill"), mcp.Required()),
-	)
-	Selector = mcp.NewTool("rod_selector",
-		mcp.WithDescription("Select an element on the page with Select tag"),
-		mcp.WithString("selector", mcp.Description("CSS selector for element to select"), mcp.Required()),
-		mcp.WithString("value", mcp.Description("Value to select"), mcp.Required()),
-	)
-	Evaluate = mcp.NewTool("rod_evaluate",
-		mcp.WithDescription("Execute JavaScript in the browser console"),
-		mcp.WithString("script", mcp.Description("JavaScript code to execute"), mcp.Required()),
-	)
+	GoBack       = newTool("rod_go_back", "Go back in the browser history, go back to the previous page", struct{}{})
+	GoForward    = newTool("rod_go_forward", "Go forward in the browser history, go to the next page", struct{}{})
+	ReLoad       = newTool("rod_reload", "Reload the current page", struct{}{})
+	PressKey     = newTool("rod_press_key", "Press a key on the keyboard", pressKeyArgs{})
+	CloseBrowser = newTool("rod_close_browser", "Close the browser", struct{}{})
+	Fill         = newTool("rod_fill", "Fill out an input field", fillArgs{})
 )
 
 type ToolHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error)
 
 var (
-	NavigationHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			url := request.Params.Arguments["url"].(string)
-			if !utils.IsHttp(url) {
-				log.Errorf("Invalid URL: %s", url)
-				return nil, errors.New("invalid URL")
-			}
-
-			page, err := rodCtx.EnsurePage()
-			if err != nil {
-				log.Errorf("Failed to navigate to %s: %s", url, err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to navigate to %s: %s", url, err.Error()))
-			}
-			err = page.Navigate(url)
-			if err != nil {
-				log.Errorf("Failed to navigate to %s: %s", url, err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to navigate to %s: %s", url, err.Error()))
-			}
-			page.WaitDOMStable(defaultWaitStableDur, defaultDomDiff)
-			return mcp.NewToolResultText(fmt.Sprintf("Navigated to %s", url)), nil
-		}
-	}
-
 	GoBackHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			page, err := rodCtx.EnsurePage()
+			page, timeout, err := activePage(ctx, rodCtx, request)
 			if err != nil {
-				log.Errorf("Failed to go back: %s", err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to go back: %s", err.Error()))
+				log.Errorf("Failed to go back: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to go back")
 			}
+			defer page.CancelTimeout()
 			err = page.NavigateBack()
 			if err != nil {
-				log.Errorf("Failed to go back: %s", err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to go back: %s", err.Error()))
+				log.Errorf("Failed to go back: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to go back")
+			}
+			if err = page.WaitDOMStable(defaultWaitStableDur, defaultDomDiff); err != nil {
+				log.Errorf("Failed to wait for the page to be stable after going back: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to wait for the page to be stable after going back")
 			}
-			page.WaitDOMStable(defaultWaitStableDur, defaultDomDiff)
 			return mcp.NewToolResultText("Go back successfully"), nil
 		}
 	}
 
 	GoForwardHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			page, err := rodCtx.EnsurePage()
+			page, timeout, err := activePage(ctx, rodCtx, request)
 			if err != nil {
-				log.Errorf("Failed to go forward: %s", err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to go forward: %s", err.Error()))
+				log.Errorf("Failed to go forward: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to go forward")
 			}
+			defer page.CancelTimeout()
 			err = page.NavigateForward()
 			if err != nil {
-				log.Errorf("Failed to go forward: %s", err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to go forward: %s", err.Error()))
+				log.Errorf("Failed to go forward: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to go forward")
+			}
+			if err = page.WaitDOMStable(defaultWaitStableDur, defaultDomDiff); err != nil {
+				log.Errorf("Failed to wait for the page to be stable after going forward: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to wait for the page to be stable after going forward")
 			}
-			page.WaitDOMStable(defaultWaitStableDur, defaultDomDiff)
 			return mcp.NewToolResultText("Go forward successfully"), nil
 		}
 	}
 
 	ReLoadHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			page, err := rodCtx.EnsurePage()
+			page, timeout, err := activePage(ctx, rodCtx, request)
 			if err != nil {
-				log.Errorf("Failed to reload current page: %s", err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to reload current page: %s", err.Error()))
+				log.Errorf("Failed to reload current page: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to reload current page")
 			}
+			defer page.CancelTimeout()
 			err = page.Reload()
 			if err != nil {
-				log.Errorf("Failed to reload current page: %s", err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to reload current page: %s", err.Error()))
+				log.Errorf("Failed to reload current page: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to reload current page")
+			}
+			if err = page.WaitDOMStable(defaultWaitStableDur, defaultDomDiff); err != nil {
+				log.Errorf("Failed to wait for the page to be stable after reloading: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to wait for the page to be stable after reloading")
 			}
-			page.WaitDOMStable(defaultWaitStableDur, defaultDomDiff)
 			return mcp.NewToolResultText("Reload current page successfully"), nil
 		}
 	}
 
 	PressKeyHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			page, err := rodCtx.EnsurePage()
-			if err != nil {
-				log.Errorf("Failed to press key: %s", err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to press key: %s", err.Error()))
+			var args pressKeyArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
 			}
-			key := request.Params.Arguments["key"].(rune)
-			err = page.Keyboard.Type(input.Key(key))
+			key, onKeyboard, err := parseKey(args.Key)
 			if err != nil {
-				log.Errorf("Failed to press key %s: %s", string(key), err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to press key %s: %s", string(key), err.Error()))
+				return nil, err
 			}
-			return mcp.NewToolResultText(fmt.Sprintf("Press key %s successfully", string(key))), nil
-		}
-	}
-
-	ClickHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			page, err := rodCtx.EnsurePage()
+			page, timeout, err := activePage(ctx, rodCtx, request)
 			if err != nil {
-				log.Errorf("Failed to click element: %s", err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to click element: %s", err.Error()))
+				log.Errorf("Failed to press key: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to press key")
 			}
-			selector := request.Params.Arguments["selector"].(string)
-			element, err := page.Element(selector)
-			if err != nil {
-				log.Errorf("Failed to find element %s: %s", selector, err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to find element %s: %s", selector, err.Error()))
+			defer page.CancelTimeout()
+			if onKeyboard {
+				var pointer *types.Pointer
+				if pointer, err = tabPointer(rodCtx, page); err == nil {
+					err = pointer.Type(page, key)
+				}
+			} else {
+				err = page.InsertText(args.Key)
 			}
-			err = element.Click(proto.InputMouseButtonLeft, 1)
 			if err != nil {
-				log.Errorf("Failed to click element %s: %s", selector, err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to click element %s: %s", selector, err.Error()))
+				log.Errorf("Failed to press key %s: %s", args.Key, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to press key %s", args.Key)
 			}
-			return mcp.NewToolResultText(fmt.Sprintf("Click element %s successfully", selector)), nil
+			return mcp.NewToolResultText(fmt.Sprintf("Press key %s successfully", args.Key)), nil
 		}
 	}
 
 	FillHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			page, err := rodCtx.EnsurePage()
+			var args fillArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			page, timeout, err := activePage(ctx, rodCtx, request)
 			if err != nil {
-				log.Errorf("Failed to fill out element: %s", err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to fill out element: %s", err.Error()))
+				log.Errorf("Failed to fill out element: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to fill out element")
 			}
-			selector := request.Params.Arguments["selector"].(string)
-			value := request.Params.Arguments["value"].(string)
-			element, err := page.Element(selector)
+			defer page.CancelTimeout()
+			element, selector, err := findElement(rodCtx, page, elementTarget{Selector: args.Selector, Ref: args.Ref})
 			if err != nil {
-				log.Errorf("Failed to find element %s: %s", selector, err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to find element %s: %s", selector, err.Error()))
+				log.Errorf("Failed to find element %s: %s", selector, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to find element %s", selector)
 			}
-			err = element.Input(value)
+			err = element.Input(args.Value)
 			if err != nil {
-				log.Errorf("Failed to fill out element %s: %s", selector, err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to fill out element %s: %s", selector, err.Error()))
+				log.Errorf("Failed to fill out element %s: %s", selector, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to fill out element %s", selector)
 			}
 			return mcp.NewToolResultText(fmt.Sprintf("Fill out element %s successfully", selector)), nil
 		}
 	}
 	CloseBrowserHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			err := rodCtx.CloseBrowser()
+			err := rodCtx.CloseBrowser(ctx)
 			if err != nil {
 				log.Errorf("Failed to close browser: %s", err.Error())
-				return nil, errors.New(fmt.Sprintf("Failed to close browser: %s", err.Error()))
+				return nil, fail(err, 0, "Failed to close browser")
 			}
 			return mcp.NewToolResultText("Close browser successfully"), nil
 		}
@@ -229,17 +176,72 @@ var (
 		ReLoad,
 		PressKey,
 		Click,
+		Hover,
 		Fill,
+		MouseMove,
+		MouseClick,
+		MouseDown,
+		MouseUp,
+		MouseDrag,
+		MouseWheel,
+		Selector,
+		Snapshot,
+		WaitFor,
+		Evaluate,
+		Screenshot,
+		Pdf,
+		ConsoleLogs,
+		NetworkRequests,
+		TabList,
+		TabNew,
+		TabSelect,
+		TabClose,
+		ProfileList,
+		ProfileSwitch,
+		StorageStateSave,
+		StorageStateLoad,
+		CookieList,
+		CookieSet,
+		CookieDelete,
+		CookieClear,
+		CookieImport,
 		CloseBrowser,
 	}
 	CommonToolHandlers = map[string]ToolHandler{
-		"rod_navigate":      NavigationHandler,
-		"rod_go_back":       GoBackHandler,
-		"rod_go_forward":    GoForwardHandler,
-		"rod_reload":        ReLoadHandler,
-		"rod_press_key":     PressKeyHandler,
-		"rod_click":         ClickHandler,
-		"rod_fill":          FillHandler,
-		"rod_close_browser": CloseBrowserHandler,
+		"rod_navigate":           NavigationHandler,
+		"rod_go_back":            GoBackHandler,
+		"rod_go_forward":         GoForwardHandler,
+		"rod_reload":             ReLoadHandler,
+		"rod_press_key":          PressKeyHandler,
+		"rod_click":              ClickHandler,
+		"rod_hover":              HoverHandler,
+		"rod_fill":               FillHandler,
+		"rod_mouse_move":         MouseMoveHandler,
+		"rod_mouse_click":        MouseClickHandler,
+		"rod_mouse_down":         MouseDownHandler,
+		"rod_mouse_up":           MouseUpHandler,
+		"rod_mouse_drag":         MouseDragHandler,
+		"rod_mouse_wheel":        MouseWheelHandler,
+		"rod_selector":           SelectorHandler,
+		"rod_snapshot":           SnapshotHandler,
+		"rod_evaluate":           EvaluateHandler,
+		"rod_screenshot":         ScreenshotHandler,
+		"rod_pdf":                PdfHandler,
+		"rod_console_logs":       ConsoleLogsHandler,
+		"rod_network_requests":   NetworkRequestsHandler,
+		"rod_tab_list":           TabListHandler,
+		"rod_tab_new":            TabNewHandler,
+		"rod_tab_select":         TabSelectHandler,
+		"rod_tab_close":          TabCloseHandler,
+		"rod_profile_list":       ProfileListHandler,
+		"rod_profile_switch":     ProfileSwitchHandler,
+		"rod_storage_state_save": StorageStateSaveHandler,
+		"rod_storage_state_load": StorageStateLoadHandler,
+		"rod_cookie_list":        CookieListHandler,
+		"rod_cookie_set":         CookieSetHandler,
+		"rod_cookie_delete":      CookieDeleteHandler,
+		"rod_cookie_clear":       CookieClearHandler,
+		"rod_cookie_import":      CookieImportHandler,
+		"rod_close_browser":      CloseBrowserHandler,
 	}
 )
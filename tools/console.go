@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type consoleLogsArgs struct {
+	Levels []string `json:"levels" description:"Only return messages of these levels, such as 'log', 'info', 'warning', 'error', 'debug' or 'exception'"`
+	Since  string   `json:"since" description:"Only return messages logged after this time, RFC3339 timestamp or unix milliseconds"`
+	Limit  int      `json:"limit" min:"1" description:"Maximum number of the newest messages to return"`
+	Clear  bool     `json:"clear" description:"Clear the collected messages after reading them"`
+}
+
+var (
+	ConsoleLogs = newTool("rod_console_logs", "Read the browser console messages and uncaught exceptions collected from the pages, optionally filtered or cleared", consoleLogsArgs{})
+)
+
+var (
+	ConsoleLogsHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args consoleLogsArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			filter := types.ConsoleFilter{Levels: args.Levels}
+			if since := args.Since; since != "" {
+				t, err := parseSince(since)
+				if err != nil {
+					return nil, invalidArgument("Invalid since %s: %s", since, err.Error())
+				}
+				filter.Since = t
+			}
+			var messages []types.ConsoleMessage
+			if args.Clear {
+				messages = rodCtx.ConsoleLogs().Drain(filter)
+			} else {
+				messages = rodCtx.ConsoleLogs().List(filter)
+			}
+			if args.Limit > 0 && args.Limit < len(messages) {
+				messages = messages[len(messages)-args.Limit:]
+			}
+			if len(messages) == 0 {
+				return mcp.NewToolResultText("No console messages"), nil
+			}
+			lines := make([]string, 0, len(messages))
+			for _, m := range messages {
+				lines = append(lines, m.String())
+			}
+			return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+		}
+	}
+)
+
+// parseSince accepts a RFC3339 timestamp or unix milliseconds
+func parseSince(since string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(since, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Parse(time.RFC3339, since)
+}
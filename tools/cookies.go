@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/go-rod/rod-mcp/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"os"
+)
+
+type cookieFilterArgs struct {
+	Domain string `json:"domain" description:"Only the cookies of this domain and its subdomains"`
+	Name   string `json:"name" description:"Only the cookies with this name"`
+}
+
+type cookieSetArgs struct {
+	Name     string  `json:"name" required:"true" description:"Name of the cookie"`
+	Value    string  `json:"value" description:"Value of the cookie"`
+	URL      string  `json:"url" description:"URL the cookie belongs to, either url or domain is required, with domain it sets the default path and secure of the cookie"`
+	Domain   string  `json:"domain" description:"Domain of the cookie, a leading dot also matches the subdomains"`
+	Path     string  `json:"path" description:"Path of the cookie"`
+	Expires  float64 `json:"expires" min:"0" description:"Expiry as unix time in seconds, a session cookie if not set"`
+	Secure   bool    `json:"secure" description:"Only send the cookie over HTTPS"`
+	HTTPOnly bool    `json:"http_only" description:"Hide the cookie from JavaScript"`
+	SameSite string  `json:"same_site" enum:"Strict,Lax,None" description:"SameSite attribute of the cookie"`
+}
+
+type cookieImportArgs struct {
+	FilePath string `json:"file_path" required:"true" description:"Path of the cookies.txt file relative to the artifacts directory"`
+}
+
+var (
+	CookieList   = newTool("rod_cookie_list", "List the cookies of the browser", cookieFilterArgs{})
+	CookieSet    = newTool("rod_cookie_set", "Set a cookie in the browser, a cookie with the same name, domain and path is replaced", cookieSetArgs{})
+	CookieDelete = newTool("rod_cookie_delete", "Delete the cookies matching the domain and name, at least one of them is required, use rod_cookie_clear to delete all cookies", cookieFilterArgs{})
+	CookieClear  = newTool("rod_cookie_clear", "Delete all the cookies of the browser", struct{}{})
+	CookieImport = newTool("rod_cookie_import", "Import the cookies of a Netscape cookies.txt file, such as the files exported by browser extensions and curl", cookieImportArgs{})
+)
+
+var (
+	CookieListHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args cookieFilterArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			cookies, err := rodCtx.Cookies(ctx, types.CookieFilter{Domain: args.Domain, Name: args.Name})
+			if err != nil {
+				log.Errorf("Failed to list cookies: %s", err.Error())
+				return nil, fail(err, 0, "Failed to list cookies")
+			}
+			if len(cookies) == 0 {
+				return mcp.NewToolResultText("No cookies"), nil
+			}
+			out, err := json.MarshalIndent(cookies, "", "  ")
+			if err != nil {
+				return nil, fail(err, 0, "Failed to encode cookies")
+			}
+			return mcp.NewToolResultText(string(out)), nil
+		}
+	}
+
+	CookieSetHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args cookieSetArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			cookie := types.StorageCookie{
+				Name:     args.Name,
+				Value:    args.Value,
+				Domain:   args.Domain,
+				Path:     args.Path,
+				Expires:  args.Expires,
+				HTTPOnly: args.HTTPOnly,
+				Secure:   args.Secure,
+				SameSite: args.SameSite,
+			}
+			url := args.URL
+			if url == "" && cookie.Domain == "" {
+				return nil, invalidArgument("url or domain is required")
+			}
+			if url != "" && !utils.IsHttp(url) {
+				return nil, invalidArgument("invalid URL")
+			}
+			if err := rodCtx.SetCookies(ctx, []types.StorageCookie{cookie}, url); err != nil {
+				log.Errorf("Failed to set cookie %s: %s", cookie.Name, err.Error())
+				return nil, fail(err, 0, "Failed to set cookie %s", cookie.Name)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Set cookie %s successfully", cookie.Name)), nil
+		}
+	}
+
+	CookieDeleteHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args cookieFilterArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			filter := types.CookieFilter{Domain: args.Domain, Name: args.Name}
+			if filter.Domain == "" && filter.Name == "" {
+				return nil, invalidArgument("domain or name is required")
+			}
+			deleted, err := rodCtx.DeleteCookies(ctx, filter)
+			if err != nil {
+				log.Errorf("Failed to delete cookies: %s", err.Error())
+				return nil, fail(err, 0, "Failed to delete cookies")
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Delete %d cookies successfully", deleted)), nil
+		}
+	}
+
+	CookieClearHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if err := rodCtx.ClearCookies(ctx); err != nil {
+				log.Errorf("Failed to clear cookies: %s", err.Error())
+				return nil, fail(err, 0, "Failed to clear cookies")
+			}
+			return mcp.NewToolResultText("Clear cookies successfully"), nil
+		}
+	}
+
+	CookieImportHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args cookieImportArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			filePath := args.FilePath
+			path, err := utils.SafeJoin(rodCtx.ArtifactsDir(), filePath)
+			if err != nil {
+				log.Errorf("Invalid cookies file path %s: %s", filePath, err.Error())
+				return nil, invalidArgument("Invalid cookies file path %s: %s", filePath, err.Error())
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, fail(err, 0, "Failed to open cookies file %s", path)
+			}
+			defer file.Close()
+			cookies, err := types.ParseNetscapeCookies(file)
+			if err != nil {
+				return nil, fail(err, 0, "Failed to parse cookies file %s", path)
+			}
+			if len(cookies) == 0 {
+				return mcp.NewToolResultText(fmt.Sprintf("No cookies in %s", path)), nil
+			}
+			if err = rodCtx.SetCookies(ctx, cookies, ""); err != nil {
+				log.Errorf("Failed to import cookies: %s", err.Error())
+				return nil, fail(err, 0, "Failed to import cookies")
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Import %d cookies from %s successfully", len(cookies), path)), nil
+		}
+	}
+)
@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod-mcp/types"
+	"time"
+)
+
+const (
+	maxSelectorHints   = 5
+	selectorHintsLimit = 2 * time.Second
+)
+
+// similarSelectorsJS lists the selectors of the page closest to the selector that matched nothing,
+// the candidates are the ids, test ids, names and classes of the elements
+const similarSelectorsJS = `(selector, max) => {
+	const distance = (a, b) => {
+		const d = Array.from({ length: b.length + 1 }, (_, i) => i);
+		for (let i = 1; i <= a.length; i++) {
+			let prev = d[0];
+			d[0] = i;
+			for (let j = 1; j <= b.length; j++) {
+				const tmp = d[j];
+				d[j] = Math.min(d[j] + 1, d[j - 1] + 1, prev + (a[i - 1] === b[j - 1] ? 0 : 1));
+				prev = tmp;
+			}
+		}
+		return d[b.length];
+	};
+	const candidates = new Set();
+	for (const el of Array.from(document.querySelectorAll('*')).slice(0, 5000)) {
+		const tag = el.tagName.toLowerCase();
+		if (el.id) candidates.add('#' + CSS.escape(el.id));
+		for (const attr of ['data-testid', 'data-test', 'name', 'aria-label']) {
+			const v = el.getAttribute(attr);
+			if (v) candidates.add(tag + '[' + attr + '=' + JSON.stringify(v) + ']');
+		}
+		for (const c of el.classList) candidates.add(tag + '.' + CSS.escape(c));
+	}
+	const target = selector.toLowerCase();
+	return Array.from(candidates)
+		.map(c => ({ c, d: distance(target, c.toLowerCase()) }))
+		.filter(x => x.d <= Math.max(3, target.length / 2))
+		.sort((a, b) => a.d - b.d)
+		.slice(0, max)
+		.map(x => x.c);
+}`
+
+// elementTarget points to the element a tool operates on, the `ref` of a snapshot wins over the selector
+type elementTarget struct {
+	Selector string
+	Ref      string
+}
+
+// set reports whether the target points to an element
+func (t elementTarget) set() bool {
+	return t.Ref != "" || t.Selector != ""
+}
+
+// findElement resolves the element a tool operates on from either the `ref` of a snapshot or the
+// `selector` argument, it also returns a description of the element for messages
+func findElement(rodCtx *types.Context, page *rod.Page, target elementTarget) (*rod.Element, string, error) {
+	if ref := target.Ref; ref != "" {
+		tab := rodCtx.TabOf(page)
+		if tab == nil {
+			return nil, ref, newToolError(CodeBrowserClosed, "the tab was closed")
+		}
+		el, err := tab.Refs.Resolve(page, ref)
+		if err != nil {
+			return nil, fmt.Sprintf("ref %s", ref), &ToolError{
+				Code:    CodeElementNotFound,
+				Message: err.Error(),
+				Hints:   []string{"Refs expire on navigation and on the next snapshot, take a new snapshot with rod_snapshot"},
+				err:     err,
+			}
+		}
+		return el, fmt.Sprintf("ref %s", ref), nil
+	}
+	selector := target.Selector
+	if selector == "" {
+		return nil, "", invalidArgument("selector or ref is required")
+	}
+	el, err := page.Element(selector)
+	if err != nil && errors.Is(err, context.DeadlineExceeded) {
+		// the element is waited for until the timeout, so a timeout means nothing matched
+		hints := similarSelectors(page, selector)
+		hints = append(hints, "Use rod_snapshot to find the interactive elements and pass their ref instead of a selector")
+		return nil, selector, &ToolError{
+			Code:    CodeElementNotFound,
+			Message: fmt.Sprintf("no element matches %s before the timeout", selector),
+			Hints:   hints,
+			err:     err,
+		}
+	}
+	return el, selector, err
+}
+
+// similarSelectors suggests selectors of the page close to the selector, the page context is already
+// done so a short timeout of its own is used
+func similarSelectors(page *rod.Page, selector string) []string {
+	page = page.Context(context.Background()).Timeout(selectorHintsLimit)
+	defer page.CancelTimeout()
+	res, err := page.Eval(similarSelectorsJS, selector, maxSelectorHints)
+	if err != nil {
+		return nil
+	}
+	var similar []string
+	if err := res.Value.Unmarshal(&similar); err != nil {
+		return nil
+	}
+	hints := make([]string, 0, len(similar))
+	for _, s := range similar {
+		hints = append(hints, fmt.Sprintf("Similar selector on the page: %s", s))
+	}
+	return hints
+}
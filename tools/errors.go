@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/mark3labs/mcp-go/mcp"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// ErrorCode is a stable identifier of a tool failure the model can act upon
+type ErrorCode string
+
+const (
+	CodeInvalidArgument        ErrorCode = "invalid_argument"
+	CodeElementNotFound        ErrorCode = "element_not_found"
+	CodeElementNotInteractable ErrorCode = "element_not_interactable"
+	CodeNavigationFailed       ErrorCode = "navigation_failed"
+	CodeEvaluationFailed       ErrorCode = "evaluation_failed"
+	CodeTimeout                ErrorCode = "timeout"
+	CodeCanceled               ErrorCode = "canceled"
+	CodeBrowserClosed          ErrorCode = "browser_closed"
+	CodeDisabled               ErrorCode = "disabled"
+	CodeInternal               ErrorCode = "internal"
+)
+
+// ToolError is a failure of a tool, it is returned to the client as a tool result with isError set
+// so that the model can recover instead of the call failing as a protocol error
+type ToolError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	Hints   []string  `json:"hints,omitempty"`
+	err     error
+}
+
+func (e *ToolError) Error() string {
+	return e.Message
+}
+
+func (e *ToolError) Unwrap() error {
+	return e.err
+}
+
+func newToolError(code ErrorCode, format string, args ...interface{}) *ToolError {
+	return &ToolError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func invalidArgument(format string, args ...interface{}) *ToolError {
+	return newToolError(CodeInvalidArgument, format, args...)
+}
+
+// fail describes the error of an action, the code is derived from the error unless it is a ToolError already
+func fail(err error, timeout time.Duration, format string, args ...interface{}) *ToolError {
+	message := fmt.Sprintf(format, args...)
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return &ToolError{Code: toolErr.Code, Message: message + ": " + toolErr.Message, Hints: toolErr.Hints, err: toolErr.err}
+	}
+	return &ToolError{Code: errorCode(err), Message: message + ": " + reason(err, timeout), err: err}
+}
+
+// errorCode classifies the errors of rod and of the CDP connection
+func errorCode(err error) ErrorCode {
+	var (
+		evalErr      *rod.EvalError
+		navErr       *rod.NavigationError
+		notFoundErr  *rod.ElementNotFoundError
+		notInteract  *rod.NotInteractableError
+		invisibleErr *rod.InvisibleShapeError
+		coveredErr   *rod.CoveredError
+		noPointerErr *rod.NoPointerEventsError
+		pageNotFound *rod.PageNotFoundError
+	)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	case errors.As(err, &notFoundErr):
+		return CodeElementNotFound
+	case errors.As(err, &notInteract), errors.As(err, &invisibleErr), errors.As(err, &coveredErr), errors.As(err, &noPointerErr):
+		return CodeElementNotInteractable
+	case errors.As(err, &navErr):
+		return CodeNavigationFailed
+	case errors.As(err, &evalErr):
+		return CodeEvaluationFailed
+	case errors.As(err, &pageNotFound), errors.Is(err, cdp.ErrSessionNotFound), errors.Is(err, io.EOF),
+		errors.Is(err, net.ErrClosed), strings.Contains(err.Error(), "Target closed"):
+		return CodeBrowserClosed
+	default:
+		return CodeInternal
+	}
+}
+
+// reason describes the error of a rod call, an aborted call tells whether the timeout was exceeded
+// or the client canceled the request
+func reason(err error, timeout time.Duration) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) && timeout > 0:
+		return fmt.Sprintf("timeout %s exceeded", timeout)
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout exceeded"
+	case errors.Is(err, context.Canceled):
+		return "request canceled"
+	default:
+		return err.Error()
+	}
+}
+
+// ErrorResult converts the error of a tool into a result with isError set, the content is the JSON
+// of the error code, message and hints
+func ErrorResult(err error) *mcp.CallToolResult {
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) {
+		toolErr = &ToolError{Code: errorCode(err), Message: err.Error(), err: err}
+	}
+	out, _ := json.MarshalIndent(toolErr, "", "  ")
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{mcp.NewTextContent(string(out))},
+		IsError: true,
+	}
+}
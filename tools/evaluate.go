@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/mark3labs/mcp-go/mcp"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// functionExprRegexp matches scripts that are already a function expression rather than a function body
+var functionExprRegexp = regexp.MustCompile(`^(async\s+)?(function\b|\([^)]*\)\s*=>|[A-Za-z_$][\w$]*\s*=>)`)
+
+type evaluateArgs struct {
+	Script       string        `json:"script" required:"true" description:"JavaScript function body to execute, such as 'return document.title', a function expression is also accepted"`
+	Args         []interface{} `json:"args" description:"JSON arguments passed to the script"`
+	Selector     string        `json:"selector" description:"CSS selector of the element bound to 'this' in the script"`
+	Ref          string        `json:"ref" description:"Ref of the element bound to 'this' in the script from rod_snapshot, used instead of selector"`
+	AwaitPromise bool          `json:"await_promise" default:"true" description:"Wait for the returned promise to settle"`
+	Timeout      float64       `json:"timeout" min:"0" description:"Timeout in milliseconds, the default comes from the server configuration"`
+}
+
+var (
+	Evaluate = newTool("rod_evaluate", "Execute JavaScript in the browser and return the result as JSON. The script is a function body, the arguments are available as 'args' and 'this' is the element when selector or ref is set, use 'return' to return a value", evaluateArgs{})
+)
+
+type evaluateResult struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+var (
+	EvaluateHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if rodCtx.Config().DisableEvaluate {
+				return nil, newToolError(CodeDisabled, "rod_evaluate is disabled by the server configuration")
+			}
+			var args evaluateArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			if strings.TrimSpace(args.Script) == "" {
+				return nil, invalidArgument("script is required")
+			}
+			timeout := rodCtx.Config().TimeoutFor(request.Params.Name)
+			if args.Timeout > 0 {
+				timeout = time.Duration(args.Timeout) * time.Millisecond
+			}
+
+			page, err := currentPage(ctx, rodCtx)
+			if err != nil {
+				log.Errorf("Failed to evaluate script: %s", err.Error())
+				return nil, fail(err, timeout, "Failed to evaluate script")
+			}
+			page = page.Context(ctx).Timeout(timeout)
+			defer page.CancelTimeout()
+
+			opts := rod.Eval(evaluateFunction(args.Script, args.AwaitPromise), args.Args...)
+			opts.AwaitPromise = args.AwaitPromise
+			if target := (elementTarget{Selector: args.Selector, Ref: args.Ref}); target.set() {
+				element, selector, err := findElement(rodCtx, page, target)
+				if err != nil {
+					log.Errorf("Failed to find element %s: %s", selector, err.Error())
+					return nil, fail(err, timeout, "Failed to find element %s", selector)
+				}
+				opts.This(element.Object)
+			}
+
+			res, err := page.Evaluate(opts)
+			if err != nil {
+				var evalErr *rod.EvalError
+				if errors.As(err, &evalErr) {
+					return nil, scriptException(evalErr)
+				}
+				if errors.Is(err, context.DeadlineExceeded) {
+					return nil, newToolError(CodeTimeout, "Evaluate script timed out after %s", timeout)
+				}
+				log.Errorf("Failed to evaluate script: %s", err.Error())
+				return nil, fail(err, timeout, "Failed to evaluate script")
+			}
+
+			result := evaluateResult{Type: string(res.Type)}
+			if res.Type != proto.RuntimeRemoteObjectTypeUndefined {
+				result.Value = res.Value.Val()
+			}
+			out, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return nil, fail(err, timeout, "Failed to encode evaluate result")
+			}
+			return mcp.NewToolResultText(string(out)), nil
+		}
+	}
+)
+
+// evaluateFunction wraps a function body into a function taking the tool arguments as `args`,
+// the function is async when the result is awaited so that the body can use `await`
+func evaluateFunction(script string, async bool) string {
+	script = strings.TrimSpace(script)
+	if functionExprRegexp.MatchString(script) {
+		return script
+	}
+	if async {
+		return fmt.Sprintf("async function (...args) {\n%s\n}", script)
+	}
+	return fmt.Sprintf("function (...args) {\n%s\n}", script)
+}
+
+// scriptException reports the exception thrown by the script with its stack trace
+func scriptException(evalErr *rod.EvalError) *ToolError {
+	details := evalErr.RuntimeExceptionDetails
+	message := details.Text
+	if details.Exception != nil {
+		if details.Exception.Description != "" {
+			message = details.Exception.Description
+		} else {
+			message = fmt.Sprintf("%s %s", details.Text, details.Exception.Value.JSON("", ""))
+		}
+	}
+	if details.StackTrace != nil && !strings.Contains(message, "\n    at ") {
+		for _, frame := range details.StackTrace.CallFrames {
+			name := frame.FunctionName
+			if name == "" {
+				name = "<anonymous>"
+			}
+			message += fmt.Sprintf("\n    at %s (%s:%d:%d)", name, frame.URL, frame.LineNumber+1, frame.ColumnNumber+1)
+		}
+	}
+	return &ToolError{Code: CodeEvaluationFailed, Message: "Script threw an exception: " + message, err: evalErr}
+}
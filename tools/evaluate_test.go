@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"errors"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ysmood/gson"
+	"testing"
+)
+
+func TestScriptException(t *testing.T) {
+	stack := &proto.RuntimeStackTrace{CallFrames: []*proto.RuntimeCallFrame{
+		{FunctionName: "check", URL: "https://example.com/app.js", LineNumber: 9, ColumnNumber: 4},
+		{URL: "", LineNumber: 0, ColumnNumber: 0},
+	}}
+	tests := []struct {
+		name    string
+		details *proto.RuntimeExceptionDetails
+		want    string
+	}{
+		{
+			name: "error with stack in the description",
+			details: &proto.RuntimeExceptionDetails{
+				Text:       "Uncaught",
+				Exception:  &proto.RuntimeRemoteObject{Description: "TypeError: x is undefined\n    at check (app.js:10:5)"},
+				StackTrace: stack,
+			},
+			want: "Script threw an exception: TypeError: x is undefined\n    at check (app.js:10:5)",
+		},
+		{
+			name: "thrown value",
+			details: &proto.RuntimeExceptionDetails{
+				Text:       "Uncaught",
+				Exception:  &proto.RuntimeRemoteObject{Value: gson.New("boom")},
+				StackTrace: stack,
+			},
+			want: "Script threw an exception: Uncaught \"boom\"\n" +
+				"    at check (https://example.com/app.js:10:5)\n" +
+				"    at <anonymous> (:1:1)",
+		},
+		{
+			name:    "text only",
+			details: &proto.RuntimeExceptionDetails{Text: "SyntaxError: Unexpected token"},
+			want:    "Script threw an exception: SyntaxError: Unexpected token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evalErr := &rod.EvalError{RuntimeExceptionDetails: tt.details}
+			got := scriptException(evalErr)
+			if got.Code != CodeEvaluationFailed {
+				t.Errorf("code = %s, want %s", got.Code, CodeEvaluationFailed)
+			}
+			if got.Message != tt.want {
+				t.Errorf("message = %q, want %q", got.Message, tt.want)
+			}
+			if !errors.Is(got, evalErr) {
+				t.Error("the tool error does not wrap the eval error")
+			}
+		})
+	}
+}
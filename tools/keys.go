@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"github.com/go-rod/rod/lib/input"
+	"unicode/utf8"
+)
+
+// namedKeys maps the names of the non printable keys to the keys of rod
+var namedKeys = map[string]input.Key{
+	"Enter":       input.Enter,
+	"Tab":         input.Tab,
+	"Space":       input.Space,
+	"Escape":      input.Escape,
+	"Backspace":   input.Backspace,
+	"Delete":      input.Delete,
+	"Insert":      input.Insert,
+	"Home":        input.Home,
+	"End":         input.End,
+	"PageUp":      input.PageUp,
+	"PageDown":    input.PageDown,
+	"ArrowLeft":   input.ArrowLeft,
+	"ArrowUp":     input.ArrowUp,
+	"ArrowRight":  input.ArrowRight,
+	"ArrowDown":   input.ArrowDown,
+	"Shift":       input.ShiftLeft,
+	"Control":     input.ControlLeft,
+	"Alt":         input.AltLeft,
+	"Meta":        input.MetaLeft,
+	"CapsLock":    input.CapsLock,
+	"ContextMenu": input.ContextMenu,
+	"F1":          input.F1,
+	"F2":          input.F2,
+	"F3":          input.F3,
+	"F4":          input.F4,
+	"F5":          input.F5,
+	"F6":          input.F6,
+	"F7":          input.F7,
+	"F8":          input.F8,
+	"F9":          input.F9,
+	"F10":         input.F10,
+	"F11":         input.F11,
+	"F12":         input.F12,
+}
+
+// parseKey resolves the key name or the single character to a key of rod, ok is false for
+// characters that are not on the keyboard layout of rod, they can only be inserted as text
+func parseKey(name string) (key input.Key, ok bool, err error) {
+	if key, ok := namedKeys[name]; ok {
+		return key, true, nil
+	}
+	r, size := utf8.DecodeRuneInString(name)
+	if size == 0 || size != len(name) || r == utf8.RuneError {
+		return 0, false, invalidArgument("Unknown key %q, use a single character or a key name such as Enter or ArrowLeft", name)
+	}
+	return input.Key(r), keyDefined(input.Key(r)), nil
+}
+
+// keyDefined reports whether rod knows the key, Info panics for unknown keys
+func keyDefined(key input.Key) (defined bool) {
+	defer func() {
+		if recover() != nil {
+			defined = false
+		}
+	}()
+	key.Info()
+	return true
+}
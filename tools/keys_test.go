@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"github.com/go-rod/rod/lib/input"
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		want       input.Key
+		onKeyboard bool
+		wantErr    bool
+	}{
+		{name: "named key", key: "Enter", want: input.Enter, onKeyboard: true},
+		{name: "named modifier", key: "Control", want: input.ControlLeft, onKeyboard: true},
+		{name: "letter", key: "a", want: input.Key('a'), onKeyboard: true},
+		{name: "digit", key: "7", want: input.Key('7'), onKeyboard: true},
+		{name: "character off the keyboard", key: "é", want: input.Key('é'), onKeyboard: false},
+		{name: "empty", key: "", wantErr: true},
+		{name: "unknown name", key: "Enterr", wantErr: true},
+		{name: "invalid utf8", key: "\xff", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, onKeyboard, err := parseKey(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseKey(%q) error = nil, want an error", tt.key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseKey(%q) error = %v", tt.key, err)
+			}
+			if key != tt.want || onKeyboard != tt.onKeyboard {
+				t.Errorf("parseKey(%q) = %v, %v, want %v, %v", tt.key, key, onKeyboard, tt.want, tt.onKeyboard)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ToolCall is a call of a tool bound to the browser context of the caller
+type ToolCall = func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+
+// Middleware wraps the calls of every tool, such as to recover panics, log or enforce policies
+type Middleware = func(next ToolCall) ToolCall
+
+// PolicyCheck decides whether a tool call is allowed, the call is rejected when an error is returned
+type PolicyCheck = func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) error
+
+// timeoutGrace is added to the timeout of the tool so that the handler can report its own timeout first
+const timeoutGrace = 5 * time.Second
+
+// Chain wraps the call with the middlewares, the first middleware is the outermost
+func Chain(call ToolCall, middlewares ...Middleware) ToolCall {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		call = middlewares[i](call)
+	}
+	return call
+}
+
+// Recover turns a panic of the tool into an internal error result, so that one broken call does not
+// take the server and the browser down with it
+func Recover() Middleware {
+	return func(next ToolCall) ToolCall {
+		return func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Errorf("Tool %s panicked: %v\n%s", request.Params.Name, r, debug.Stack())
+					result, err = nil, newToolError(CodeInternal, "Tool %s panicked: %v", request.Params.Name, r)
+				}
+			}()
+			return next(ctx, rodCtx, request)
+		}
+	}
+}
+
+// Logging logs the outcome and the duration of every call
+func Logging() Middleware {
+	return func(next ToolCall) ToolCall {
+		return func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			result, err := next(ctx, rodCtx, request)
+			if err != nil {
+				log.Warnf("Tool %s failed after %s: [%s] %s", request.Params.Name, time.Since(start), codeOf(err), err.Error())
+			} else {
+				log.Debugf("Tool %s done in %s", request.Params.Name, time.Since(start))
+			}
+			return result, err
+		}
+	}
+}
+
+// ReportRecovery tells the model that the browser or the active tab was relaunched during the call
+// after a crash, so that it knows the page state it relied on is gone
+func ReportRecovery() Middleware {
+	return func(next ToolCall) ToolCall {
+		return func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, rodCtx, request)
+			note := rodCtx.TakeRecovery()
+			if note == "" {
+				return result, err
+			}
+			if err != nil {
+				var toolErr *ToolError
+				if !errors.As(err, &toolErr) {
+					toolErr = &ToolError{Code: errorCode(err), Message: err.Error(), err: err}
+				}
+				toolErr.Hints = append(toolErr.Hints, note)
+				return nil, toolErr
+			}
+			if result != nil {
+				result.Content = append([]mcp.Content{mcp.NewTextContent(note)}, result.Content...)
+			}
+			return result, nil
+		}
+	}
+}
+
+// KeepAlive marks the browser context busy during the call, the browser is neither closed for
+// idleness nor recycled while a call is running
+func KeepAlive() Middleware {
+	return func(next ToolCall) ToolCall {
+		return func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			done := rodCtx.Busy()
+			defer done()
+			return next(ctx, rodCtx, request)
+		}
+	}
+}
+
+// Timeout bounds the context of the call by the timeout of the tool, it is a backstop for the rod
+// calls that are not bound to a page, the timeout argument of a tool overrides the configured one
+func Timeout() Middleware {
+	return func(next ToolCall) ToolCall {
+		return func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx, cancel := context.WithTimeout(ctx, callTimeout(rodCtx.Config(), request)+timeoutGrace)
+			defer cancel()
+			return next(ctx, rodCtx, request)
+		}
+	}
+}
+
+// Policy rejects the calls the check does not allow with the disabled error code
+func Policy(check PolicyCheck) Middleware {
+	return func(next ToolCall) ToolCall {
+		return func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if err := check(ctx, rodCtx, request); err != nil {
+				var toolErr *ToolError
+				if errors.As(err, &toolErr) {
+					return nil, toolErr
+				}
+				return nil, &ToolError{Code: CodeDisabled, Message: fmt.Sprintf("Tool %s is not allowed: %s", request.Params.Name, err.Error()), err: err}
+			}
+			return next(ctx, rodCtx, request)
+		}
+	}
+}
+
+func callTimeout(cfg types.Config, request mcp.CallToolRequest) time.Duration {
+	if v, ok := request.Params.Arguments["timeout"].(float64); ok && v > 0 {
+		return time.Duration(v) * time.Millisecond
+	}
+	if request.Params.Name == Navigation.Name && cfg.NavigationTimeout > 0 {
+		return cfg.NavigationTimeout
+	}
+	return cfg.TimeoutFor(request.Params.Name)
+}
+
+func codeOf(err error) ErrorCode {
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		return toolErr.Code
+	}
+	return errorCode(err)
+}
+
+// ToolStats are the counters of a tool
+type ToolStats struct {
+	Name          string         `json:"name"`
+	Calls         int64          `json:"calls"`
+	Errors        int64          `json:"errors"`
+	Panics        int64          `json:"panics"`
+	ErrorCodes    map[string]int `json:"errorCodes,omitempty"`
+	TotalDuration time.Duration  `json:"totalDuration"`
+	MaxDuration   time.Duration  `json:"maxDuration"`
+}
+
+// Metrics counts the calls, failures and durations of the tools
+type Metrics struct {
+	tools map[string]*ToolStats
+	lock  sync.Mutex
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{tools: make(map[string]*ToolStats)}
+}
+
+// Middleware records the calls of the tools, it must be inside Recover to see the panics
+func (m *Metrics) Middleware() Middleware {
+	return func(next ToolCall) ToolCall {
+		return func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+			start := time.Now()
+			defer func() {
+				r := recover()
+				m.record(request.Params.Name, time.Since(start), err, r != nil)
+				if r != nil {
+					panic(r)
+				}
+			}()
+			return next(ctx, rodCtx, request)
+		}
+	}
+}
+
+func (m *Metrics) record(name string, duration time.Duration, err error, panicked bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	stats, ok := m.tools[name]
+	if !ok {
+		stats = &ToolStats{Name: name, ErrorCodes: make(map[string]int)}
+		m.tools[name] = stats
+	}
+	stats.Calls++
+	stats.TotalDuration += duration
+	if duration > stats.MaxDuration {
+		stats.MaxDuration = duration
+	}
+	switch {
+	case panicked:
+		stats.Panics++
+	case err != nil:
+		stats.Errors++
+		stats.ErrorCodes[string(codeOf(err))]++
+	}
+}
+
+// Snapshot returns a copy of the counters sorted by tool name
+func (m *Metrics) Snapshot() []ToolStats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	snapshot := make([]ToolStats, 0, len(m.tools))
+	for _, stats := range m.tools {
+		s := *stats
+		s.ErrorCodes = make(map[string]int, len(stats.ErrorCodes))
+		for code, n := range stats.ErrorCodes {
+			s.ErrorCodes[code] = n
+		}
+		snapshot = append(snapshot, s)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Name < snapshot[j].Name })
+	return snapshot
+}
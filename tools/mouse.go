@@ -0,0 +1,307 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const coordinatesPage = "page"
+
+// viewportPointJS converts a point of the page to the viewport, the page is scrolled first when the
+// point is outside of the viewport
+const viewportPointJS = `(x, y) => {
+	const w = window.innerWidth, h = window.innerHeight;
+	if (x < window.scrollX || x >= window.scrollX + w || y < window.scrollY || y >= window.scrollY + h) {
+		window.scrollTo({ left: x - w / 2, top: y - h / 2, behavior: 'instant' });
+	}
+	return [x - window.scrollX, y - window.scrollY];
+}`
+
+type mousePoint struct {
+	X float64 `json:"x" required:"true" description:"Horizontal coordinate in CSS pixels"`
+	Y float64 `json:"y" required:"true" description:"Vertical coordinate in CSS pixels"`
+}
+
+type mouseMoveArgs struct {
+	X           float64 `json:"x" required:"true" description:"Horizontal coordinate in CSS pixels"`
+	Y           float64 `json:"y" required:"true" description:"Vertical coordinate in CSS pixels"`
+	Coordinates string  `json:"coordinates" enum:"viewport,page" default:"viewport" description:"Whether the coordinates are relative to the viewport, as in a screenshot of the viewport, or to the page, as in a full page screenshot"`
+	Steps       int     `json:"steps" min:"1" max:"100" default:"1" description:"Number of intermediate mouse moves from the current position, more steps trigger more mousemove events"`
+}
+
+type mouseClickArgs struct {
+	X           float64  `json:"x" required:"true" description:"Horizontal coordinate in CSS pixels"`
+	Y           float64  `json:"y" required:"true" description:"Vertical coordinate in CSS pixels"`
+	Coordinates string   `json:"coordinates" enum:"viewport,page" default:"viewport" description:"Whether the coordinates are relative to the viewport, as in a screenshot of the viewport, or to the page, as in a full page screenshot"`
+	Button      string   `json:"button" enum:"left,right,middle" default:"left" description:"Mouse button to click with"`
+	ClickCount  int      `json:"click_count" min:"1" max:"3" default:"1" description:"Number of clicks, 2 for a double click"`
+	Modifiers   []string `json:"modifiers" enum:"Alt,Control,Meta,Shift" description:"Modifier keys held down during the click"`
+}
+
+type mouseButtonArgs struct {
+	X           *float64 `json:"x" description:"Horizontal coordinate in CSS pixels to move to first, the current position if not set"`
+	Y           *float64 `json:"y" description:"Vertical coordinate in CSS pixels to move to first, the current position if not set"`
+	Coordinates string   `json:"coordinates" enum:"viewport,page" default:"viewport" description:"Whether the coordinates are relative to the viewport, as in a screenshot of the viewport, or to the page, as in a full page screenshot"`
+	Button      string   `json:"button" enum:"left,right,middle" default:"left" description:"Mouse button to press or release"`
+}
+
+type mouseDragArgs struct {
+	Path        []mousePoint `json:"path" required:"true" description:"Points to drag along, the button is pressed at the first point and released at the last one"`
+	Coordinates string       `json:"coordinates" enum:"viewport,page" default:"viewport" description:"Whether the coordinates are relative to the viewport, as in a screenshot of the viewport, or to the page, as in a full page screenshot"`
+	Button      string       `json:"button" enum:"left,right,middle" default:"left" description:"Mouse button held down while dragging"`
+	Steps       int          `json:"steps" min:"1" max:"100" default:"10" description:"Number of mouse moves between two points of the path"`
+	Modifiers   []string     `json:"modifiers" enum:"Alt,Control,Meta,Shift" description:"Modifier keys held down while dragging"`
+}
+
+type mouseWheelArgs struct {
+	X           float64  `json:"x" required:"true" description:"Horizontal coordinate in CSS pixels of the point to scroll at"`
+	Y           float64  `json:"y" required:"true" description:"Vertical coordinate in CSS pixels of the point to scroll at"`
+	Coordinates string   `json:"coordinates" enum:"viewport,page" default:"viewport" description:"Whether the coordinates are relative to the viewport, as in a screenshot of the viewport, or to the page, as in a full page screenshot"`
+	DeltaX      float64  `json:"delta_x" description:"Horizontal scroll distance in CSS pixels, positive scrolls right"`
+	DeltaY      float64  `json:"delta_y" description:"Vertical scroll distance in CSS pixels, positive scrolls down"`
+	Steps       int      `json:"steps" min:"1" max:"100" default:"1" description:"Number of wheel events the distance is split into"`
+	Modifiers   []string `json:"modifiers" enum:"Alt,Control,Meta,Shift" description:"Modifier keys held down while scrolling, such as 'Control' to zoom maps"`
+}
+
+var (
+	MouseMove  = newTool("rod_mouse_move", "Move the mouse to a point of the page, such as a point seen in a screenshot, for canvas apps, maps and other content without selectors", mouseMoveArgs{})
+	MouseClick = newTool("rod_mouse_click", "Click at a point of the page, such as a point seen in a screenshot, for canvas apps, maps and other content without selectors", mouseClickArgs{})
+	MouseDown  = newTool("rod_mouse_down", "Press a mouse button down, optionally after moving to a point, release it with rod_mouse_up", mouseButtonArgs{})
+	MouseUp    = newTool("rod_mouse_up", "Release a mouse button pressed with rod_mouse_down, optionally after moving to a point", mouseButtonArgs{})
+	MouseDrag  = newTool("rod_mouse_drag", "Drag the mouse along a path of points with a button held down, such as to draw, move a map or drag a slider", mouseDragArgs{})
+	MouseWheel = newTool("rod_mouse_wheel", "Scroll the mouse wheel at a point of the page, such as to scroll a list or zoom a map", mouseWheelArgs{})
+)
+
+var (
+	MouseMoveHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args mouseMoveArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			page, timeout, err := activePage(ctx, rodCtx, request)
+			if err != nil {
+				log.Errorf("Failed to move mouse: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to move mouse")
+			}
+			defer page.CancelTimeout()
+			pointer, err := tabPointer(rodCtx, page)
+			if err == nil {
+				var point proto.Point
+				if point, err = viewportPoint(page, mousePoint{X: args.X, Y: args.Y}, args.Coordinates); err == nil {
+					err = pointer.MoveLinear(page, point, args.Steps)
+				}
+			}
+			if err != nil {
+				log.Errorf("Failed to move mouse to (%v, %v): %s", args.X, args.Y, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to move mouse to (%v, %v)", args.X, args.Y)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Move mouse to (%v, %v) successfully", args.X, args.Y)), nil
+		}
+	}
+
+	MouseClickHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args mouseClickArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			page, timeout, err := activePage(ctx, rodCtx, request)
+			if err != nil {
+				log.Errorf("Failed to click: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to click")
+			}
+			defer page.CancelTimeout()
+			pointer, err := tabPointer(rodCtx, page)
+			if err == nil {
+				var point proto.Point
+				if point, err = viewportPoint(page, mousePoint{X: args.X, Y: args.Y}, args.Coordinates); err == nil {
+					err = withModifiers(page, pointer, args.Modifiers, func() error {
+						if err := pointer.MoveTo(page, point); err != nil {
+							return err
+						}
+						return pointer.Click(page, proto.InputMouseButton(args.Button), args.ClickCount)
+					})
+				}
+			}
+			if err != nil {
+				log.Errorf("Failed to click at (%v, %v): %s", args.X, args.Y, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to click at (%v, %v)", args.X, args.Y)
+			}
+			description := describeClick(clickArgs{Button: args.Button, ClickCount: args.ClickCount, Modifiers: args.Modifiers})
+			return mcp.NewToolResultText(fmt.Sprintf("%s at (%v, %v) successfully", description, args.X, args.Y)), nil
+		}
+	}
+
+	MouseDownHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mouseButton(ctx, rodCtx, request, true)
+		}
+	}
+
+	MouseUpHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return mouseButton(ctx, rodCtx, request, false)
+		}
+	}
+
+	MouseDragHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args mouseDragArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			if len(args.Path) < 2 {
+				return nil, invalidArgument("path must have at least 2 points")
+			}
+			page, timeout, err := activePage(ctx, rodCtx, request)
+			if err != nil {
+				log.Errorf("Failed to drag: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to drag")
+			}
+			defer page.CancelTimeout()
+			pointer, err := tabPointer(rodCtx, page)
+			if err != nil {
+				log.Errorf("Failed to drag: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to drag")
+			}
+			button := proto.InputMouseButton(args.Button)
+			err = withModifiers(page, pointer, args.Modifiers, func() error {
+				start, err := viewportPoint(page, args.Path[0], args.Coordinates)
+				if err != nil {
+					return err
+				}
+				if err = pointer.MoveTo(page, start); err != nil {
+					return err
+				}
+				if err = pointer.Down(page, button, 1); err != nil {
+					return err
+				}
+				for _, p := range args.Path[1:] {
+					point, err := viewportPoint(page, p, args.Coordinates)
+					if err == nil {
+						err = pointer.MoveLinear(page, point, args.Steps)
+					}
+					if err != nil {
+						// do not leave the button pressed for the next tools, even when the call timed out
+						release := restoringPage(page)
+						_ = pointer.Up(release, button, 1)
+						release.CancelTimeout()
+						return err
+					}
+				}
+				return pointer.Up(page, button, 1)
+			})
+			if err != nil {
+				log.Errorf("Failed to drag along %d points: %s", len(args.Path), reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to drag along %d points", len(args.Path))
+			}
+			last := args.Path[len(args.Path)-1]
+			return mcp.NewToolResultText(fmt.Sprintf("Drag from (%v, %v) to (%v, %v) along %d points successfully", args.Path[0].X, args.Path[0].Y, last.X, last.Y, len(args.Path))), nil
+		}
+	}
+
+	MouseWheelHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args mouseWheelArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			if args.DeltaX == 0 && args.DeltaY == 0 {
+				return nil, invalidArgument("delta_x or delta_y is required")
+			}
+			page, timeout, err := activePage(ctx, rodCtx, request)
+			if err != nil {
+				log.Errorf("Failed to scroll: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to scroll")
+			}
+			defer page.CancelTimeout()
+			pointer, err := tabPointer(rodCtx, page)
+			if err == nil {
+				var point proto.Point
+				if point, err = viewportPoint(page, mousePoint{X: args.X, Y: args.Y}, args.Coordinates); err == nil {
+					err = withModifiers(page, pointer, args.Modifiers, func() error {
+						if err := pointer.MoveTo(page, point); err != nil {
+							return err
+						}
+						return pointer.Scroll(page, args.DeltaX, args.DeltaY, args.Steps)
+					})
+				}
+			}
+			if err != nil {
+				log.Errorf("Failed to scroll at (%v, %v): %s", args.X, args.Y, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to scroll at (%v, %v)", args.X, args.Y)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Scroll (%v, %v) at (%v, %v) successfully", args.DeltaX, args.DeltaY, args.X, args.Y)), nil
+		}
+	}
+)
+
+// mouseButton presses or releases the button of rod_mouse_down and rod_mouse_up
+func mouseButton(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest, down bool) (*mcp.CallToolResult, error) {
+	var args mouseButtonArgs
+	if err := decodeArgs(request, &args); err != nil {
+		return nil, err
+	}
+	if (args.X == nil) != (args.Y == nil) {
+		return nil, invalidArgument("x and y must be set together")
+	}
+	action, done := "release", "Release"
+	if down {
+		action, done = "press", "Press"
+	}
+	page, timeout, err := activePage(ctx, rodCtx, request)
+	if err != nil {
+		log.Errorf("Failed to %s mouse button: %s", action, reason(err, timeout))
+		return nil, fail(err, timeout, "Failed to %s mouse button", action)
+	}
+	defer page.CancelTimeout()
+	pointer, err := tabPointer(rodCtx, page)
+	if err != nil {
+		log.Errorf("Failed to %s mouse button: %s", action, reason(err, timeout))
+		return nil, fail(err, timeout, "Failed to %s mouse button", action)
+	}
+	if args.X != nil {
+		point, err := viewportPoint(page, mousePoint{X: *args.X, Y: *args.Y}, args.Coordinates)
+		if err == nil {
+			err = pointer.MoveTo(page, point)
+		}
+		if err != nil {
+			log.Errorf("Failed to move mouse to (%v, %v): %s", *args.X, *args.Y, reason(err, timeout))
+			return nil, fail(err, timeout, "Failed to move mouse to (%v, %v)", *args.X, *args.Y)
+		}
+	}
+	button := proto.InputMouseButton(args.Button)
+	if down {
+		err = pointer.Down(page, button, 1)
+	} else {
+		err = pointer.Up(page, button, 1)
+	}
+	if err != nil {
+		log.Errorf("Failed to %s mouse button %s: %s", action, args.Button, reason(err, timeout))
+		return nil, fail(err, timeout, "Failed to %s mouse button %s", action, args.Button)
+	}
+	position := pointer.Position()
+	return mcp.NewToolResultText(fmt.Sprintf("%s mouse button %s at viewport (%v, %v) successfully", done, args.Button, position.X, position.Y)), nil
+}
+
+// viewportPoint converts the point to the viewport coordinates the mouse events use
+func viewportPoint(page *rod.Page, point mousePoint, coordinates string) (proto.Point, error) {
+	if coordinates != coordinatesPage {
+		return proto.NewPoint(point.X, point.Y), nil
+	}
+	res, err := page.Eval(viewportPointJS, point.X, point.Y)
+	if err != nil {
+		return proto.Point{}, err
+	}
+	xy := res.Value.Arr()
+	if len(xy) != 2 {
+		return proto.Point{}, fmt.Errorf("unexpected viewport point %s", res.Value.JSON("", ""))
+	}
+	return proto.NewPoint(xy[0].Num(), xy[1].Num()), nil
+}
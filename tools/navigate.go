@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/go-rod/rod-mcp/utils"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/mark3labs/mcp-go/mcp"
+	"strings"
+	"sync"
+	"time"
+)
+
+type navigateArgs struct {
+	URL       string  `json:"url" required:"true" description:"URL to navigate to"`
+	WaitUntil string  `json:"wait_until" enum:"load,domcontentloaded,network-idle,dom-stable,none" description:"When the navigation is done: 'load' waits for the load event, 'domcontentloaded' for the DOMContentLoaded event, 'network-idle' until no request is made for 500ms, 'dom-stable' until the DOM stops changing and 'none' returns as soon as the response arrives, the default comes from the server configuration"`
+	Timeout   float64 `json:"timeout" min:"0" description:"Timeout in milliseconds, the default comes from the server configuration"`
+}
+
+var (
+	Navigation = newTool("rod_navigate", "Navigate to a URL and return the final URL, HTTP status, title and redirects of the page", navigateArgs{})
+)
+
+var (
+	NavigationHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args navigateArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			url := args.URL
+			if !utils.IsHttp(url) {
+				log.Errorf("Invalid URL: %s", url)
+				return nil, invalidArgument("invalid URL")
+			}
+			cfg := rodCtx.Config()
+			waitUntil := args.WaitUntil
+			if waitUntil == "" {
+				waitUntil = cfg.NavigationWaitUntil
+			}
+			if waitUntil == "" {
+				waitUntil = types.DefaultNavigationWaitUntil
+			}
+			timeout := cfg.NavigationTimeout
+			if args.Timeout > 0 {
+				timeout = time.Duration(args.Timeout) * time.Millisecond
+			}
+			if timeout <= 0 {
+				timeout = types.DefaultNavigationTimeout
+			}
+
+			page, err := currentPage(ctx, rodCtx)
+			if err != nil {
+				log.Errorf("Failed to navigate to %s: %s", url, err.Error())
+				return nil, fail(err, timeout, "Failed to navigate to %s", url)
+			}
+			page = page.Context(ctx).Timeout(timeout)
+			defer page.CancelTimeout()
+
+			wait, err := navigationWaiter(page, waitUntil)
+			if err != nil {
+				return nil, err
+			}
+			nav, stop := recordNavigation(page)
+			defer stop()
+			if err = page.Navigate(url); err != nil {
+				log.Errorf("Failed to navigate to %s: %s", url, err.Error())
+				return nil, fail(err, timeout, "Failed to navigate to %s", url)
+			}
+			if err = wait(); err != nil {
+				if errors.Is(err, context.DeadlineExceeded) {
+					return nil, newToolError(CodeTimeout, "Timeout %s exceeded while waiting for %s of %s", timeout, waitUntil, url)
+				}
+				log.Errorf("Failed to wait for %s of %s: %s", waitUntil, url, err.Error())
+				return nil, fail(err, timeout, "Failed to wait for %s of %s", waitUntil, url)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Navigated to %s\n%s", url, nav.describe(page))), nil
+		}
+	}
+)
+
+// navigationWaiter prepares the wait of the strategy, it must be called before the navigation starts
+// so that the events of the navigation are not missed
+func navigationWaiter(page *rod.Page, waitUntil string) (func() error, error) {
+	lifecycle := func(name proto.PageLifecycleEventName) func() error {
+		wait := page.WaitNavigation(name)
+		return func() error {
+			wait()
+			return page.GetContext().Err()
+		}
+	}
+	switch waitUntil {
+	case types.WaitUntilLoad:
+		return lifecycle(proto.PageLifecycleEventNameLoad), nil
+	case types.WaitUntilDOMContentLoaded:
+		return lifecycle(proto.PageLifecycleEventNameDOMContentLoaded), nil
+	case types.WaitUntilNetworkIdle:
+		return lifecycle(proto.PageLifecycleEventNameNetworkIdle), nil
+	case types.WaitUntilDOMStable:
+		return func() error {
+			return page.WaitDOMStable(defaultWaitStableDur, defaultDomDiff)
+		}, nil
+	case types.WaitUntilNone:
+		return func() error { return nil }, nil
+	default:
+		// wait_until is checked against its enum, only navigationWaitUntil of the config can get here
+		return nil, newToolError(CodeInternal, "Unsupported navigationWaitUntil %s in the server config", waitUntil)
+	}
+}
+
+type redirectHop struct {
+	URL    string
+	Status int
+}
+
+// navigationRecord is the main frame document response of a navigation
+type navigationRecord struct {
+	lock       sync.Mutex
+	requestID  proto.NetworkRequestID
+	redirects  []redirectHop
+	status     int
+	statusText string
+}
+
+// recordNavigation records the document requests of the main frame until stop is called
+func recordNavigation(page *rod.Page) (*navigationRecord, func()) {
+	eventCtx, stop := context.WithCancel(page.GetContext())
+	r := &navigationRecord{}
+	wait := page.Context(eventCtx).EachEvent(func(e *proto.NetworkRequestWillBeSent) {
+		if e.Type != proto.NetworkResourceTypeDocument || e.FrameID != page.FrameID {
+			return
+		}
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		if r.requestID == "" {
+			r.requestID = e.RequestID
+		}
+		if e.RequestID == r.requestID && e.RedirectResponse != nil {
+			r.redirects = append(r.redirects, redirectHop{URL: e.RedirectResponse.URL, Status: e.RedirectResponse.Status})
+		}
+	}, func(e *proto.NetworkResponseReceived) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		if e.RequestID == r.requestID {
+			r.status = e.Response.Status
+			r.statusText = e.Response.StatusText
+		}
+	})
+	go wait()
+	return r, stop
+}
+
+func (r *navigationRecord) describe(page *rod.Page) string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var b strings.Builder
+	if info, err := page.Info(); err == nil {
+		fmt.Fprintf(&b, "- Final URL: %s\n- Title: %s\n", info.URL, info.Title)
+	}
+	if r.status != 0 {
+		fmt.Fprintf(&b, "- Status: %d %s\n", r.status, r.statusText)
+	}
+	if len(r.redirects) > 0 {
+		hops := make([]string, 0, len(r.redirects))
+		for _, hop := range r.redirects {
+			hops = append(hops, fmt.Sprintf("%s (%d)", hop.URL, hop.Status))
+		}
+		fmt.Fprintf(&b, "- Redirects: %s\n", strings.Join(hops, " -> "))
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
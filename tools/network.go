@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"regexp"
+)
+
+type networkRequestsArgs struct {
+	URLPattern     string   `json:"url_pattern" description:"Regular expression the request URL must match"`
+	Methods        []string `json:"methods" description:"Only return requests of these HTTP methods"`
+	ResourceTypes  []string `json:"resource_types" description:"Only return requests of these resource types, such as 'XHR', 'Fetch', 'Document' or 'Script'"`
+	MinStatus      int      `json:"min_status" min:"0" description:"Only return responses with a status code greater than or equal to this"`
+	MaxStatus      int      `json:"max_status" min:"0" description:"Only return responses with a status code less than or equal to this"`
+	FailedOnly     bool     `json:"failed_only" description:"Only return requests that failed to load"`
+	Since          string   `json:"since" description:"Only return requests started after this time, RFC3339 timestamp or unix milliseconds"`
+	Limit          int      `json:"limit" min:"1" description:"Maximum number of the newest requests to return"`
+	IncludeHeaders bool     `json:"include_headers" description:"Include the recorded headers, requires networkCaptureHeaders in the config"`
+	IncludeBodies  bool     `json:"include_bodies" description:"Include the recorded bodies, requires networkCaptureBodies in the config"`
+	Clear          bool     `json:"clear" description:"Clear the recorded requests after reading them"`
+}
+
+var (
+	NetworkRequests = newTool("rod_network_requests", "List the network requests made by the browser pages, such as XHR and fetch calls, with their status, timing and size", networkRequestsArgs{})
+)
+
+var (
+	NetworkRequestsHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args networkRequestsArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			filter := types.NetworkFilter{
+				Methods:       args.Methods,
+				ResourceTypes: args.ResourceTypes,
+				MinStatus:     args.MinStatus,
+				MaxStatus:     args.MaxStatus,
+				FailedOnly:    args.FailedOnly,
+			}
+			if pattern := args.URLPattern; pattern != "" {
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return nil, invalidArgument("Invalid url_pattern %s: %s", pattern, err.Error())
+				}
+				filter.URLPattern = re
+			}
+			if since := args.Since; since != "" {
+				t, err := parseSince(since)
+				if err != nil {
+					return nil, invalidArgument("Invalid since %s: %s", since, err.Error())
+				}
+				filter.Since = t
+			}
+
+			var requests []types.NetworkRequest
+			if args.Clear {
+				requests = rodCtx.NetworkLogs().Drain(filter)
+			} else {
+				requests = rodCtx.NetworkLogs().List(filter)
+			}
+			if args.Limit > 0 && args.Limit < len(requests) {
+				requests = requests[len(requests)-args.Limit:]
+			}
+			if len(requests) == 0 {
+				return mcp.NewToolResultText("No network requests"), nil
+			}
+
+			for i := range requests {
+				if !args.IncludeHeaders {
+					requests[i].RequestHeaders, requests[i].ResponseHeaders = nil, nil
+				}
+				if !args.IncludeBodies {
+					requests[i].RequestBody, requests[i].ResponseBody = "", ""
+				}
+			}
+			out, err := json.MarshalIndent(requests, "", "  ")
+			if err != nil {
+				return nil, fail(err, 0, "Failed to encode network requests")
+			}
+			return mcp.NewToolResultText(string(out)), nil
+		}
+	}
+)
@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"context"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"time"
+)
+
+type queuedTabKey struct{}
+
+// withQueuedTab records the tab the call holds the turn of in the action queue
+func withQueuedTab(ctx context.Context, tab *types.Tab) context.Context {
+	return context.WithValue(ctx, queuedTabKey{}, tab)
+}
+
+// currentPage returns the page the call operates on, the tab queued for the call when there is one
+// so that a tab switch while waiting does not move the call to a tab it does not hold, otherwise
+// the active tab
+func currentPage(ctx context.Context, rodCtx *types.Context) (*rod.Page, error) {
+	if tab, ok := ctx.Value(queuedTabKey{}).(*types.Tab); ok {
+		return rodCtx.TabPage(tab)
+	}
+	return rodCtx.EnsurePage()
+}
+
+// activePage returns the page of the active tab bound to the request, the rod calls on the page are
+// aborted when the client cancels the request or the timeout of the tool is exceeded,
+// page.CancelTimeout must be called once the tool is done
+func activePage(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) (*rod.Page, time.Duration, error) {
+	timeout := rodCtx.Config().TimeoutFor(request.Params.Name)
+	page, err := currentPage(ctx, rodCtx)
+	if err != nil {
+		return nil, timeout, err
+	}
+	return page.Context(ctx).Timeout(timeout), timeout, nil
+}
+
+// restoreLimit bounds the calls undoing the changes a tool made to the page, such as releasing the
+// held keys and buttons or resetting an emulation
+const restoreLimit = 2 * time.Second
+
+// restoringPage returns the page for the calls undoing the changes of a tool, it is not bound to the
+// request so that the changes are undone even after the call was canceled or timed out,
+// page.CancelTimeout must be called once done
+func restoringPage(page *rod.Page) *rod.Page {
+	return page.Context(context.Background()).Timeout(restoreLimit)
+}
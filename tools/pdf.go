@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/go-rod/rod-mcp/utils"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/mark3labs/mcp-go/mcp"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// paperSizes in inches, keyed by the lower-case paper format name
+var paperSizes = map[string][2]float64{
+	"letter":  {8.5, 11},
+	"legal":   {8.5, 14},
+	"tabloid": {11, 17},
+	"ledger":  {17, 11},
+	"a3":      {11.69, 16.54},
+	"a4":      {8.27, 11.69},
+	"a5":      {5.83, 8.27},
+	"a6":      {4.13, 5.83},
+}
+
+type pdfArgs struct {
+	FilePath          string   `json:"file_path" description:"Directory relative to the artifacts directory to save the PDF file in"`
+	FileName          string   `json:"file_name" required:"true" description:"Name of the PDF file"`
+	PaperFormat       string   `json:"paper_format" enum:"letter,legal,tabloid,ledger,a3,a4,a5,a6" default:"letter" description:"Paper format of the PDF, ignored when paper_width and paper_height are set"`
+	PaperWidth        *float64 `json:"paper_width" min:"0" description:"Paper width in inches"`
+	PaperHeight       *float64 `json:"paper_height" min:"0" description:"Paper height in inches"`
+	Landscape         bool     `json:"landscape" description:"Print in landscape orientation"`
+	MarginTop         *float64 `json:"margin_top" min:"0" description:"Top margin in inches (default: 0.4)"`
+	MarginBottom      *float64 `json:"margin_bottom" min:"0" description:"Bottom margin in inches (default: 0.4)"`
+	MarginLeft        *float64 `json:"margin_left" min:"0" description:"Left margin in inches (default: 0.4)"`
+	MarginRight       *float64 `json:"margin_right" min:"0" description:"Right margin in inches (default: 0.4)"`
+	Scale             *float64 `json:"scale" min:"0.1" max:"2" description:"Scale of the webpage rendering (default: 1)"`
+	PageRanges        string   `json:"page_ranges" description:"Page ranges to print, such as '1-5, 8, 11-13', print all pages if not set"`
+	PrintBackground   bool     `json:"print_background" description:"Print background graphics"`
+	HeaderTemplate    string   `json:"header_template" description:"HTML template for the print header, enables header and footer when set"`
+	FooterTemplate    string   `json:"footer_template" description:"HTML template for the print footer, enables header and footer when set"`
+	PreferCSSPageSize bool     `json:"prefer_css_page_size" description:"Prefer page size as defined by css over the paper format"`
+	EmulatePrintMedia bool     `json:"emulate_print_media" default:"true" description:"Emulate the print media type instead of screen while printing"`
+}
+
+var (
+	Pdf = newTool("rod_pdf", "Generate a PDF from the current page, the PDF is saved in the artifacts directory", pdfArgs{})
+)
+
+var (
+	PdfHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args pdfArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			fileName := filepath.Base(args.FileName)
+			if !strings.HasSuffix(strings.ToLower(fileName), ".pdf") {
+				fileName += ".pdf"
+			}
+			filePath := args.FilePath
+			dir, err := utils.SafeJoin(rodCtx.ArtifactsDir(), filePath)
+			if err != nil {
+				log.Errorf("Invalid PDF file path %s: %s", filePath, err.Error())
+				return nil, invalidArgument("Invalid PDF file path %s: %s", filePath, err.Error())
+			}
+
+			req, err := pdfRequest(args)
+			if err != nil {
+				return nil, err
+			}
+
+			page, timeout, err := activePage(ctx, rodCtx, request)
+			if err != nil {
+				log.Errorf("Failed to generate PDF: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to generate PDF")
+			}
+			defer page.CancelTimeout()
+
+			if args.EmulatePrintMedia {
+				err = proto.EmulationSetEmulatedMedia{Media: "print"}.Call(page)
+				if err != nil {
+					log.Errorf("Failed to emulate print media: %s", reason(err, timeout))
+					return nil, fail(err, timeout, "Failed to emulate print media")
+				}
+				defer func() {
+					// the page may be timed out already, reset the media on a fresh context
+					_ = proto.EmulationSetEmulatedMedia{}.Call(restoringPage(page))
+				}()
+			}
+
+			reader, err := page.PDF(req)
+			if err != nil {
+				log.Errorf("Failed to generate PDF: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to generate PDF")
+			}
+			bin, err := io.ReadAll(reader)
+			if err != nil {
+				log.Errorf("Failed to read PDF stream: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to read PDF stream")
+			}
+
+			if err = os.MkdirAll(dir, 0755); err != nil {
+				log.Errorf("Failed to create PDF directory %s: %s", dir, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to create PDF directory %s", dir)
+			}
+			savedPath := filepath.Join(dir, fileName)
+			if err = os.WriteFile(savedPath, bin, 0644); err != nil {
+				log.Errorf("Failed to save PDF %s: %s", savedPath, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to save PDF %s", savedPath)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("PDF saved to %s, %d bytes", savedPath, len(bin))), nil
+		}
+	}
+)
+
+// pdfRequest builds the print request from the tool arguments
+func pdfRequest(args pdfArgs) (*proto.PagePrintToPDF, error) {
+	req := &proto.PagePrintToPDF{
+		Landscape:           args.Landscape,
+		PrintBackground:     args.PrintBackground,
+		PreferCSSPageSize:   args.PreferCSSPageSize,
+		PageRanges:          args.PageRanges,
+		HeaderTemplate:      args.HeaderTemplate,
+		FooterTemplate:      args.FooterTemplate,
+		DisplayHeaderFooter: args.HeaderTemplate != "" || args.FooterTemplate != "",
+		MarginTop:           args.MarginTop,
+		MarginBottom:        args.MarginBottom,
+		MarginLeft:          args.MarginLeft,
+		MarginRight:         args.MarginRight,
+		Scale:               args.Scale,
+	}
+
+	size, ok := paperSizes[strings.ToLower(args.PaperFormat)]
+	if !ok {
+		return nil, invalidArgument("Unsupported paper format %s", args.PaperFormat)
+	}
+	width, height := size[0], size[1]
+	if args.PaperWidth != nil {
+		width = *args.PaperWidth
+	}
+	if args.PaperHeight != nil {
+		height = *args.PaperHeight
+	}
+	if width <= 0 || height <= 0 {
+		return nil, invalidArgument("paper width and height must be greater than 0")
+	}
+	req.PaperWidth, req.PaperHeight = &width, &height
+	return req, nil
+}
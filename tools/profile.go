@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"strings"
+)
+
+type profileSwitchArgs struct {
+	Name string `json:"name" required:"true" description:"Name of the profile to switch to, use rod_profile_list to find it"`
+}
+
+var (
+	ProfileList   = newTool("rod_profile_list", "List the browser profiles of the configuration, the current profile is marked", struct{}{})
+	ProfileSwitch = newTool("rod_profile_switch", "Switch the browser profile, the browser is closed and launched again with the profile on the next tool call, so the open tabs are lost", profileSwitchArgs{})
+)
+
+var (
+	ProfileListHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			profiles := rodCtx.Config().Profiles
+			current := rodCtx.Profile()
+			if len(profiles) == 0 {
+				return mcp.NewToolResultText("No profiles configured, the browser runs with an ephemeral profile"), nil
+			}
+			lines := make([]string, 0, len(profiles)+1)
+			if current == "" {
+				lines = append(lines, "* (ephemeral)")
+			}
+			for _, profile := range profiles {
+				marker := " "
+				if profile.Name == current {
+					marker = "*"
+				}
+				lines = append(lines, fmt.Sprintf("%s %s", marker, profile))
+			}
+			return mcp.NewToolResultText(strings.Join(lines, "\n")), nil
+		}
+	}
+
+	ProfileSwitchHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args profileSwitchArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			name := args.Name
+			if err := rodCtx.SwitchProfile(ctx, name); err != nil {
+				log.Errorf("Failed to switch to profile %s: %s", name, err.Error())
+				return nil, fail(err, 0, "Failed to switch to profile %s", name)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Switch to profile %s successfully", name)), nil
+		}
+	}
+)
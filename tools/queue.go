@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type queueAccess int
+
+const (
+	// tabRead tools only look at the page and run concurrently
+	tabRead queueAccess = iota + 1
+	// tabWrite tools change the page, such as its refs or its input state, and run one at a time
+	tabWrite
+	// browserRead tools look at the whole browser, they only wait for the browserWrite tools
+	browserRead
+	// browserWrite tools change the whole browser, such as its cookies or its tabs, they wait for the
+	// running tools of all the tabs and run alone
+	browserWrite
+)
+
+// toolAccesses of the tools acting on the browser, the tools missing from the map do not wait, such as
+// the tools reading the collected logs or the configuration. rod_wait_for does not wait either, it
+// would hold back the actions it is waiting for. The tab tools queue on the active tab so that a switch
+// does not happen between the actions queued before it, closing a tab waits for all the tabs as any
+// tab can be closed
+var toolAccesses = map[string]queueAccess{
+	"rod_navigate":           tabWrite,
+	"rod_go_back":            tabWrite,
+	"rod_go_forward":         tabWrite,
+	"rod_reload":             tabWrite,
+	"rod_press_key":          tabWrite,
+	"rod_click":              tabWrite,
+	"rod_hover":              tabWrite,
+	"rod_mouse_move":         tabWrite,
+	"rod_mouse_click":        tabWrite,
+	"rod_mouse_down":         tabWrite,
+	"rod_mouse_up":           tabWrite,
+	"rod_mouse_drag":         tabWrite,
+	"rod_mouse_wheel":        tabWrite,
+	"rod_fill":               tabWrite,
+	"rod_selector":           tabWrite,
+	"rod_snapshot":           tabWrite,
+	"rod_evaluate":           tabWrite,
+	"rod_pdf":                tabWrite,
+	"rod_storage_state_load": tabWrite,
+	"rod_tab_new":            tabWrite,
+	"rod_tab_select":         tabWrite,
+	"rod_screenshot":         tabRead,
+	"rod_storage_state_save": tabRead,
+	"rod_tab_list":           browserRead,
+	"rod_cookie_list":        browserRead,
+	"rod_tab_close":          browserWrite,
+	"rod_cookie_set":         browserWrite,
+	"rod_cookie_delete":      browserWrite,
+	"rod_cookie_clear":       browserWrite,
+	"rod_cookie_import":      browserWrite,
+	"rod_profile_switch":     browserWrite,
+	"rod_close_browser":      browserWrite,
+}
+
+// TabQueue runs the calls acting on the browser through the action queues, the tab tools through the
+// queue of the active tab and the browser tools through the queue of the browser. The time spent
+// waiting for the turn is bounded by the timeout of the tool
+func TabQueue() Middleware {
+	return func(next ToolCall) ToolCall {
+		return func(ctx context.Context, rodCtx *types.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			access, ok := accessOf(request)
+			if !ok {
+				return next(ctx, rodCtx, request)
+			}
+			timeout := callTimeout(rodCtx.Config(), request)
+			waitCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			var (
+				tab     *types.Tab
+				release func()
+				err     error
+			)
+			switch access {
+			case browserRead, browserWrite:
+				release, err = rodCtx.QueueBrowserAction(waitCtx, access == browserRead)
+			default:
+				tab, release, err = rodCtx.QueueAction(waitCtx, access == tabRead)
+			}
+			if err != nil {
+				if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+					return nil, newToolError(CodeTimeout, "Timeout %s exceeded while waiting for the running actions of the browser", timeout)
+				}
+				return nil, fail(err, timeout, "Failed to queue %s", request.Params.Name)
+			}
+			defer release()
+			if tab != nil {
+				ctx = withQueuedTab(ctx, tab)
+			}
+			return next(ctx, rodCtx, request)
+		}
+	}
+}
+
+func accessOf(request mcp.CallToolRequest) (queueAccess, bool) {
+	access, ok := toolAccesses[request.Params.Name]
+	if access == tabRead && request.Params.Name == Screenshot.Name {
+		// resizing the viewport changes the page for the other tools
+		width, _ := request.Params.Arguments["width"].(float64)
+		height, _ := request.Params.Arguments["height"].(float64)
+		if width > 0 || height > 0 {
+			access = tabWrite
+		}
+	}
+	return access, ok
+}
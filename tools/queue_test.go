@@ -0,0 +1,25 @@
+package tools
+
+import "testing"
+
+// unqueuedTools do not act on the browser or must not wait for it
+var unqueuedTools = map[string]bool{
+	"rod_wait_for":         true,
+	"rod_console_logs":     true,
+	"rod_network_requests": true,
+	"rod_profile_list":     true,
+}
+
+func TestToolAccesses(t *testing.T) {
+	for _, tool := range CommonTools {
+		_, queued := toolAccesses[tool.Name]
+		if queued == unqueuedTools[tool.Name] {
+			t.Errorf("tool %s: queued = %v, want %v", tool.Name, queued, !unqueuedTools[tool.Name])
+		}
+	}
+	for name := range toolAccesses {
+		if _, ok := CommonToolHandlers[name]; !ok {
+			t.Errorf("queued tool %s has no handler", name)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/go-rod/rod-mcp/utils"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/mark3labs/mcp-go/mcp"
+	"os"
+	"path/filepath"
+)
+
+type screenshotArgs struct {
+	Name     string `json:"name" required:"true" description:"Name of the screenshot, used as the file name when saving to disk"`
+	Selector string `json:"selector" description:"CSS selector of the element to take a screenshot of"`
+	Ref      string `json:"ref" description:"Ref of the element to take a screenshot of from rod_snapshot, used instead of selector"`
+	Width    int    `json:"width" min:"0" description:"Viewport width in pixels, keep the current viewport if not set"`
+	Height   int    `json:"height" min:"0" description:"Viewport height in pixels, keep the current viewport if not set"`
+	FullPage bool   `json:"full_page" description:"Capture the full scrollable page instead of the viewport, ignored when selector or ref is set"`
+	Format   string `json:"format" enum:"png,jpeg,webp" default:"png" description:"Image format of the screenshot"`
+	Quality  int    `json:"quality" min:"0" max:"100" default:"80" description:"Compression quality from 0 to 100, only for jpeg and webp"`
+	Save     bool   `json:"save" description:"Save the screenshot in the artifacts directory"`
+	FilePath string `json:"file_path" description:"Directory relative to the artifacts directory to save the screenshot in, implies save"`
+}
+
+var (
+	Screenshot = newTool("rod_screenshot", "Take a screenshot of the current page or a specific element, the image is returned inline", screenshotArgs{})
+)
+
+var (
+	ScreenshotHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args screenshotArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			name, format := args.Name, args.Format
+			captureFormat, mimeType, ok := screenshotFormat(format)
+			if !ok {
+				return nil, invalidArgument("Unsupported screenshot format %s", format)
+			}
+			quality := args.Quality
+
+			page, timeout, err := activePage(ctx, rodCtx, request)
+			if err != nil {
+				log.Errorf("Failed to take screenshot: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to take screenshot")
+			}
+			defer page.CancelTimeout()
+			if args.Width > 0 || args.Height > 0 {
+				previous, err := resizeViewport(page, args.Width, args.Height)
+				if err != nil {
+					log.Errorf("Failed to set viewport: %s", reason(err, timeout))
+					return nil, fail(err, timeout, "Failed to set viewport")
+				}
+				// the resize only applies to the screenshot, the next tools see the page as it was
+				defer func() {
+					page := restoringPage(page)
+					defer page.CancelTimeout()
+					if err := setViewport(page, previous); err != nil {
+						log.Warnf("Failed to restore viewport: %s", err.Error())
+					}
+				}()
+			}
+
+			var bin []byte
+			if target := (elementTarget{Selector: args.Selector, Ref: args.Ref}); target.set() {
+				if captureFormat == proto.PageCaptureScreenshotFormatWebp {
+					return nil, invalidArgument("webp format is not supported for element screenshots")
+				}
+				element, selector, err := findElement(rodCtx, page, target)
+				if err != nil {
+					log.Errorf("Failed to find element %s: %s", selector, reason(err, timeout))
+					return nil, fail(err, timeout, "Failed to find element %s", selector)
+				}
+				bin, err = element.Screenshot(captureFormat, quality)
+				if err != nil {
+					log.Errorf("Failed to take screenshot of element %s: %s", selector, reason(err, timeout))
+					return nil, fail(err, timeout, "Failed to take screenshot of element %s", selector)
+				}
+			} else {
+				req := &proto.PageCaptureScreenshot{Format: captureFormat}
+				if captureFormat != proto.PageCaptureScreenshotFormatPng {
+					req.Quality = &quality
+				}
+				bin, err = page.Screenshot(args.FullPage, req)
+				if err != nil {
+					log.Errorf("Failed to take screenshot: %s", reason(err, timeout))
+					return nil, fail(err, timeout, "Failed to take screenshot")
+				}
+			}
+
+			message := fmt.Sprintf("Screenshot %s taken", name)
+			if args.Save || args.FilePath != "" {
+				dir, err := utils.SafeJoin(rodCtx.ArtifactsDir(), args.FilePath)
+				if err != nil {
+					log.Errorf("Invalid screenshot file path %s: %s", args.FilePath, reason(err, timeout))
+					return nil, fail(err, timeout, "Invalid screenshot file path %s", args.FilePath)
+				}
+				savedPath, err := saveScreenshot(dir, name, format, bin)
+				if err != nil {
+					log.Errorf("Failed to save screenshot %s: %s", name, reason(err, timeout))
+					return nil, fail(err, timeout, "Failed to save screenshot %s", name)
+				}
+				message = fmt.Sprintf("Screenshot %s taken and saved to %s", name, savedPath)
+			}
+			return mcp.NewToolResultImage(message, base64.StdEncoding.EncodeToString(bin), mimeType), nil
+		}
+	}
+)
+
+// screenshotFormat maps the format argument to the CDP capture format and its MIME type
+func screenshotFormat(format string) (proto.PageCaptureScreenshotFormat, string, bool) {
+	switch format {
+	case "png":
+		return proto.PageCaptureScreenshotFormatPng, "image/png", true
+	case "jpeg", "jpg":
+		return proto.PageCaptureScreenshotFormatJpeg, "image/jpeg", true
+	case "webp":
+		return proto.PageCaptureScreenshotFormatWebp, "image/webp", true
+	}
+	return "", "", false
+}
+
+// viewport is the size of the viewport of a page in CSS pixels and its device pixel ratio
+type viewport struct {
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Scale  float64 `json:"scale"`
+}
+
+// resizeViewport overrides the viewport size keeping the device pixel ratio of the page, a zero
+// dimension keeps the current value. The viewport before the resize is returned to restore it
+func resizeViewport(page *rod.Page, width, height int) (viewport, error) {
+	var current viewport
+	res, err := page.Eval(`() => ({ width: window.innerWidth, height: window.innerHeight, scale: window.devicePixelRatio })`)
+	if err != nil {
+		return current, err
+	}
+	if err := res.Value.Unmarshal(&current); err != nil {
+		return current, err
+	}
+	return current, setViewport(page, resizedViewport(current, width, height))
+}
+
+// resizedViewport is the current viewport with the dimensions that are set replaced
+func resizedViewport(current viewport, width, height int) viewport {
+	resized := current
+	if width > 0 {
+		resized.Width = width
+	}
+	if height > 0 {
+		resized.Height = height
+	}
+	if resized.Scale <= 0 {
+		resized.Scale = 1
+	}
+	return resized
+}
+
+func setViewport(page *rod.Page, v viewport) error {
+	return page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+		Width:             v.Width,
+		Height:            v.Height,
+		DeviceScaleFactor: v.Scale,
+	})
+}
+
+func saveScreenshot(dir, name, format string, bin []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	fileName := filepath.Base(name)
+	if filepath.Ext(fileName) == "" {
+		fileName = fmt.Sprintf("%s.%s", fileName, format)
+	}
+	savedPath := filepath.Join(dir, fileName)
+	if err := os.WriteFile(savedPath, bin, 0644); err != nil {
+		return "", err
+	}
+	return savedPath, nil
+}
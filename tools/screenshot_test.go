@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"github.com/go-rod/rod/lib/proto"
+	"testing"
+)
+
+func TestScreenshotArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		want    screenshotArgs
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			args: map[string]interface{}{"name": "shot"},
+			want: screenshotArgs{Name: "shot", Format: "png", Quality: 80},
+		},
+		{
+			name: "viewport",
+			args: map[string]interface{}{"name": "shot", "width": float64(390), "height": "", "format": "jpeg", "quality": float64(50)},
+			want: screenshotArgs{Name: "shot", Width: 390, Format: "jpeg", Quality: 50},
+		},
+		{
+			name:    "missing name",
+			args:    map[string]interface{}{},
+			wantErr: "name is required",
+		},
+		{
+			name:    "negative width",
+			args:    map[string]interface{}{"name": "shot", "width": float64(-1)},
+			wantErr: "width must be greater than or equal to 0, got -1",
+		},
+		{
+			name:    "quality out of range",
+			args:    map[string]interface{}{"name": "shot", "quality": float64(101)},
+			wantErr: "quality must be less than or equal to 100, got 101",
+		},
+		{
+			name:    "unknown format",
+			args:    map[string]interface{}{"name": "shot", "format": "gif"},
+			wantErr: `format must be one of png, jpeg, webp, got "gif"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got screenshotArgs
+			err := decodeArgs(callRequest(tt.args), &got)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("decodeArgs() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeArgs() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("decodeArgs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenshotFormat(t *testing.T) {
+	tests := []struct {
+		format   string
+		want     proto.PageCaptureScreenshotFormat
+		mimeType string
+		ok       bool
+	}{
+		{format: "png", want: proto.PageCaptureScreenshotFormatPng, mimeType: "image/png", ok: true},
+		{format: "jpeg", want: proto.PageCaptureScreenshotFormatJpeg, mimeType: "image/jpeg", ok: true},
+		{format: "jpg", want: proto.PageCaptureScreenshotFormatJpeg, mimeType: "image/jpeg", ok: true},
+		{format: "webp", want: proto.PageCaptureScreenshotFormatWebp, mimeType: "image/webp", ok: true},
+		{format: "gif"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, mimeType, ok := screenshotFormat(tt.format)
+			if got != tt.want || mimeType != tt.mimeType || ok != tt.ok {
+				t.Errorf("screenshotFormat(%q) = %s, %s, %v, want %s, %s, %v", tt.format, got, mimeType, ok, tt.want, tt.mimeType, tt.ok)
+			}
+		})
+	}
+}
+
+func TestResizedViewport(t *testing.T) {
+	current := viewport{Width: 1280, Height: 800, Scale: 2}
+	tests := []struct {
+		name          string
+		current       viewport
+		width, height int
+		want          viewport
+	}{
+		{name: "both", current: current, width: 390, height: 844, want: viewport{Width: 390, Height: 844, Scale: 2}},
+		{name: "width only", current: current, width: 390, want: viewport{Width: 390, Height: 800, Scale: 2}},
+		{name: "height only", current: current, height: 600, want: viewport{Width: 1280, Height: 600, Scale: 2}},
+		{name: "unknown scale", current: viewport{Width: 1280, Height: 800}, width: 390, want: viewport{Width: 390, Height: 800, Scale: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resizedViewport(tt.current, tt.width, tt.height); got != tt.want {
+				t.Errorf("resizedViewport() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScreenshotAccess(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want queueAccess
+	}{
+		{name: "capture only", args: map[string]interface{}{"name": "shot"}, want: tabRead},
+		{name: "resize", args: map[string]interface{}{"name": "shot", "width": float64(390)}, want: tabWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := callRequest(tt.args)
+			request.Params.Name = Screenshot.Name
+			if got, ok := accessOf(request); !ok || got != tt.want {
+				t.Errorf("accessOf() = %v, %v, want %v, true", got, ok, tt.want)
+			}
+		})
+	}
+}
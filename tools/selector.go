@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"strings"
+)
+
+// selectOptionsJS selects the matched options of a select element and fires the same events as a user would
+const selectOptionsJS = `function (values, by, append) {
+	if (!(this instanceof HTMLSelectElement)) {
+		throw new Error('element is not a <select> element');
+	}
+	if (this.disabled) {
+		throw new Error('select element is disabled');
+	}
+	if (!this.multiple && values.length > 1) {
+		throw new Error('select element does not accept multiple values');
+	}
+	const options = Array.from(this.options);
+	const matchers = values.map(v => {
+		switch (by) {
+		case 'value':
+			return (o) => o.value === v;
+		case 'label':
+			return (o) => o.label.trim() === v.trim() || o.text.trim() === v.trim();
+		case 'regex': {
+			const re = new RegExp(v);
+			return (o) => re.test(o.label) || re.test(o.text);
+		}
+		case 'index':
+			return (o, i) => i === Number(v);
+		}
+		throw new Error('unsupported match type ' + by);
+	});
+	const matched = new Set();
+	matchers.forEach((match, i) => {
+		const idx = options.findIndex((o, oi) => !o.disabled && match(o, oi));
+		if (idx < 0) {
+			throw new Error('no option matches ' + JSON.stringify(values[i]) + ' by ' + by);
+		}
+		matched.add(idx);
+		if (this.multiple) {
+			options.forEach((o, oi) => { if (oi !== idx && !o.disabled && match(o, oi)) matched.add(oi); });
+		}
+	});
+	this.focus();
+	options.forEach((o, i) => {
+		if (matched.has(i)) {
+			o.selected = true;
+		} else if (!append || !this.multiple) {
+			o.selected = false;
+		}
+	});
+	this.dispatchEvent(new Event('input', { bubbles: true, composed: true }));
+	this.dispatchEvent(new Event('change', { bubbles: true }));
+	return options.filter(o => o.selected).map(o => ({ index: o.index, value: o.value, label: o.label }));
+}`
+
+type selectorArgs struct {
+	Selector string   `json:"selector" description:"CSS selector for the select element"`
+	Ref      string   `json:"ref" description:"Ref of the select element from rod_snapshot, used instead of selector"`
+	Value    *string  `json:"value" description:"Value to select, use values to select several options"`
+	Values   []string `json:"values" description:"Values to select, only multiple selects accept more than one value"`
+	MatchBy  string   `json:"match_by" enum:"value,label,regex,index" default:"value" description:"How the values are matched against the options"`
+	Append   bool     `json:"append" description:"Keep the options already selected in a multiple select"`
+}
+
+var (
+	Selector = newTool("rod_selector", "Select options of a <select> element on the page, supports single and multiple selects", selectorArgs{})
+)
+
+type selectedOption struct {
+	Index int    `json:"index"`
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+var (
+	SelectorHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args selectorArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			target := elementTarget{Selector: args.Selector, Ref: args.Ref}
+			if !target.set() {
+				return nil, invalidArgument("selector or ref is required")
+			}
+			values := make([]string, 0)
+			if args.Value != nil {
+				values = append(values, *args.Value)
+			}
+			values = append(values, args.Values...)
+			if len(values) == 0 {
+				return nil, invalidArgument("value or values is required")
+			}
+
+			page, timeout, err := activePage(ctx, rodCtx, request)
+			if err != nil {
+				log.Errorf("Failed to select option: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to select option")
+			}
+			defer page.CancelTimeout()
+			element, selector, err := findElement(rodCtx, page, target)
+			if err != nil {
+				log.Errorf("Failed to find element %s: %s", selector, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to find element %s", selector)
+			}
+			res, err := element.Eval(selectOptionsJS, values, args.MatchBy, args.Append)
+			if err != nil {
+				log.Errorf("Failed to select option of element %s: %s", selector, reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to select option of element %s", selector)
+			}
+			var selected []selectedOption
+			if err = res.Value.Unmarshal(&selected); err != nil {
+				return nil, fail(err, timeout, "Failed to read selected options")
+			}
+			labels := make([]string, 0, len(selected))
+			for _, o := range selected {
+				labels = append(labels, fmt.Sprintf("%q", o.Label))
+			}
+			detail, _ := json.Marshal(selected)
+			return mcp.NewToolResultText(fmt.Sprintf("Select %s of element %s successfully, selected options: %s", strings.Join(labels, ", "), selector, detail)), nil
+		}
+	}
+)
@@ -0,0 +1,222 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/mark3labs/mcp-go/mcp"
+	"strings"
+)
+
+// interactiveRoles are the accessibility roles that get a ref in the snapshot
+var interactiveRoles = map[string]bool{
+	"button":           true,
+	"checkbox":         true,
+	"combobox":         true,
+	"link":             true,
+	"listbox":          true,
+	"menuitem":         true,
+	"menuitemcheckbox": true,
+	"menuitemradio":    true,
+	"option":           true,
+	"radio":            true,
+	"searchbox":        true,
+	"slider":           true,
+	"spinbutton":       true,
+	"switch":           true,
+	"tab":              true,
+	"textbox":          true,
+	"treeitem":         true,
+}
+
+// skippedRoles carry no information for the agent, their children are promoted to the parent
+var skippedRoles = map[string]bool{
+	"generic":       true,
+	"none":          true,
+	"presentation":  true,
+	"InlineTextBox": true,
+	"LineBreak":     true,
+}
+
+// snapshotStates are the accessibility properties rendered as node states
+var snapshotStates = []proto.AccessibilityAXPropertyName{
+	proto.AccessibilityAXPropertyNameChecked,
+	proto.AccessibilityAXPropertyNameDisabled,
+	proto.AccessibilityAXPropertyNameExpanded,
+	proto.AccessibilityAXPropertyNameFocused,
+	proto.AccessibilityAXPropertyNameLevel,
+	proto.AccessibilityAXPropertyNamePressed,
+	proto.AccessibilityAXPropertyNameRequired,
+	proto.AccessibilityAXPropertyNameSelected,
+}
+
+type snapshotArgs struct {
+	InteractiveOnly bool `json:"interactive_only" description:"Only list the elements that have a ref"`
+}
+
+var (
+	Snapshot = newTool("rod_snapshot", "Capture an accessibility snapshot of the current page, this is better than a screenshot to decide what to interact with. Interactive elements get a ref such as 'e5' which can be passed to other tools instead of a CSS selector, refs are invalidated by the next snapshot or a navigation", snapshotArgs{})
+)
+
+var (
+	SnapshotHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args snapshotArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			interactiveOnly := args.InteractiveOnly
+			page, timeout, err := activePage(ctx, rodCtx, request)
+			if err != nil {
+				log.Errorf("Failed to take snapshot: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to take snapshot")
+			}
+			defer page.CancelTimeout()
+			tab := rodCtx.TabOf(page)
+			if tab == nil {
+				return nil, newToolError(CodeBrowserClosed, "Failed to take snapshot: the tab was closed")
+			}
+			tree, err := proto.AccessibilityGetFullAXTree{}.Call(page)
+			if err != nil {
+				log.Errorf("Failed to take snapshot: %s", reason(err, timeout))
+				return nil, fail(err, timeout, "Failed to take snapshot")
+			}
+
+			s := newSnapshotBuilder(tree.Nodes, interactiveOnly)
+			body := s.render()
+			tab.Refs.Replace(s.refs)
+
+			header := ""
+			if info, err := page.Info(); err == nil {
+				header = fmt.Sprintf("- Page URL: %s\n- Page Title: %s\n", info.URL, info.Title)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("%s- Page Snapshot:\n```yaml\n%s```", header, body)), nil
+		}
+	}
+)
+
+type snapshotBuilder struct {
+	nodes           map[proto.AccessibilityAXNodeID]*proto.AccessibilityAXNode
+	root            *proto.AccessibilityAXNode
+	interactiveOnly bool
+	refs            map[string]proto.DOMBackendNodeID
+	out             strings.Builder
+}
+
+func newSnapshotBuilder(nodes []*proto.AccessibilityAXNode, interactiveOnly bool) *snapshotBuilder {
+	s := &snapshotBuilder{
+		nodes:           make(map[proto.AccessibilityAXNodeID]*proto.AccessibilityAXNode, len(nodes)),
+		interactiveOnly: interactiveOnly,
+		refs:            map[string]proto.DOMBackendNodeID{},
+	}
+	for _, node := range nodes {
+		s.nodes[node.NodeID] = node
+		if s.root == nil && node.ParentID == "" {
+			s.root = node
+		}
+	}
+	return s
+}
+
+func (s *snapshotBuilder) render() string {
+	if s.root != nil {
+		s.walk(s.root, 0, "")
+	}
+	return s.out.String()
+}
+
+func (s *snapshotBuilder) walk(node *proto.AccessibilityAXNode, depth int, parentName string) {
+	role := axString(node.Role)
+	name := strings.TrimSpace(axString(node.Name))
+	printed := false
+
+	switch {
+	case node.Ignored || skippedRoles[role] || (role == "RootWebArea" && depth == 0):
+	case role == "StaticText":
+		if !s.interactiveOnly && name != "" && name != parentName {
+			s.line(depth, fmt.Sprintf("text: %s", quoteYAML(name)))
+		}
+	default:
+		line, ref := s.describe(node, role, name)
+		if ref != "" || !s.interactiveOnly {
+			s.line(depth, line)
+			printed = true
+		}
+	}
+
+	childDepth := depth
+	if printed {
+		childDepth++
+		parentName = name
+	}
+	for _, id := range node.ChildIDs {
+		if child, ok := s.nodes[id]; ok {
+			s.walk(child, childDepth, parentName)
+		}
+	}
+}
+
+// describe renders a node as `role "name" [state] [ref=eN]: value`
+func (s *snapshotBuilder) describe(node *proto.AccessibilityAXNode, role, name string) (string, string) {
+	var b strings.Builder
+	b.WriteString(role)
+	if name != "" {
+		b.WriteString(" ")
+		b.WriteString(quoteYAML(name))
+	}
+	for _, prop := range node.Properties {
+		for _, state := range snapshotStates {
+			if prop.Name != state {
+				continue
+			}
+			value := axString(prop.Value)
+			switch value {
+			case "", "false":
+			case "true":
+				fmt.Fprintf(&b, " [%s]", prop.Name)
+			default:
+				fmt.Fprintf(&b, " [%s=%s]", prop.Name, value)
+			}
+		}
+	}
+	ref := ""
+	if interactiveRoles[role] && node.BackendDOMNodeID != 0 {
+		ref = fmt.Sprintf("e%d", len(s.refs)+1)
+		s.refs[ref] = node.BackendDOMNodeID
+		fmt.Fprintf(&b, " [ref=%s]", ref)
+	}
+	if value := strings.TrimSpace(axString(node.Value)); value != "" {
+		b.WriteString(": ")
+		b.WriteString(quoteYAML(value))
+	}
+	return b.String(), ref
+}
+
+func (s *snapshotBuilder) line(depth int, text string) {
+	s.out.WriteString(strings.Repeat("  ", depth))
+	s.out.WriteString("- ")
+	s.out.WriteString(text)
+	s.out.WriteString("\n")
+}
+
+func axString(v *proto.AccessibilityAXValue) string {
+	if v == nil || v.Value.Nil() {
+		return ""
+	}
+	if str, ok := v.Value.Val().(string); ok {
+		return str
+	}
+	return v.Value.JSON("", "")
+}
+
+// quoteYAML quotes the text and keeps it on a single line
+func quoteYAML(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	const maxLen = 200
+	if r := []rune(text); len(r) > maxLen {
+		text = string(r[:maxLen]) + "..."
+	}
+	return fmt.Sprintf("%q", text)
+}
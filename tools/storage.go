@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/go-rod/rod-mcp/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type storageStateArgs struct {
+	FilePath string `json:"file_path" default:"storage-state.json" description:"Path of the file relative to the artifacts directory"`
+}
+
+var (
+	StorageStateSave = newTool("rod_storage_state_save", "Save the cookies and the localStorage and sessionStorage of the open origins into a JSON file compatible with the Playwright storageState format, the file holds credentials", storageStateArgs{})
+	StorageStateLoad = newTool("rod_storage_state_load", "Load a storage state file saved by rod_storage_state_save or Playwright into the browser, the active tab visits each origin to restore its storages and then returns to the current page", storageStateArgs{})
+)
+
+var (
+	StorageStateSaveHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args storageStateArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			path, err := storageStatePath(rodCtx, args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			state, err := rodCtx.SaveStorageState(ctx)
+			if err != nil {
+				log.Errorf("Failed to save storage state: %s", err.Error())
+				return nil, fail(err, 0, "Failed to save storage state")
+			}
+			if err = types.WriteStorageState(path, state); err != nil {
+				log.Errorf("Failed to save storage state: %s", err.Error())
+				return nil, fail(err, 0, "Failed to save storage state")
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Storage state saved to %s, %d cookies, %d origins", path, len(state.Cookies), len(state.Origins))), nil
+		}
+	}
+
+	StorageStateLoadHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args storageStateArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			path, err := storageStatePath(rodCtx, args.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			state, err := types.ReadStorageState(path)
+			if err != nil {
+				log.Errorf("Failed to load storage state: %s", err.Error())
+				return nil, fail(err, 0, "Failed to load storage state")
+			}
+			page, err := currentPage(ctx, rodCtx)
+			if err != nil {
+				log.Errorf("Failed to load storage state: %s", err.Error())
+				return nil, fail(err, 0, "Failed to load storage state")
+			}
+			if err = rodCtx.LoadStorageState(page.Context(ctx), state); err != nil {
+				log.Errorf("Failed to load storage state: %s", err.Error())
+				return nil, fail(err, 0, "Failed to load storage state")
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Storage state loaded from %s, %d cookies, %d origins", path, len(state.Cookies), len(state.Origins))), nil
+		}
+	}
+)
+
+func storageStatePath(rodCtx *types.Context, filePath string) (string, error) {
+	path, err := utils.SafeJoin(rodCtx.ArtifactsDir(), filePath)
+	if err != nil {
+		log.Errorf("Invalid storage state file path %s: %s", filePath, err.Error())
+		return "", invalidArgument("Invalid storage state file path %s: %s", filePath, err.Error())
+	}
+	return path, nil
+}
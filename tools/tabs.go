@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/go-rod/rod-mcp/utils"
+	"github.com/mark3labs/mcp-go/mcp"
+	"strings"
+)
+
+type tabNewArgs struct {
+	URL      string `json:"url" description:"URL to open in the new tab, a blank tab is opened if not set"`
+	Activate bool   `json:"activate" default:"true" description:"Make the new tab the active tab"`
+}
+
+type tabSelectArgs struct {
+	ID string `json:"id" required:"true" description:"Id of the tab to switch to, use rod_tab_list to find it"`
+}
+
+type tabCloseArgs struct {
+	ID string `json:"id" description:"Id of the tab to close, the active tab is closed if not set"`
+}
+
+var (
+	TabList   = newTool("rod_tab_list", "List the browser tabs with their id, title and URL, the active tab is marked", struct{}{})
+	TabNew    = newTool("rod_tab_new", "Open a new browser tab", tabNewArgs{})
+	TabSelect = newTool("rod_tab_select", "Switch the active tab, the following tools operate on the active tab", tabSelectArgs{})
+	TabClose  = newTool("rod_tab_close", "Close a browser tab", tabCloseArgs{})
+)
+
+var (
+	TabListHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			tabs := rodCtx.Tabs()
+			if len(tabs) == 0 {
+				return mcp.NewToolResultText("No open tabs"), nil
+			}
+			return mcp.NewToolResultText(describeTabs(ctx, tabs, rodCtx.ActiveTab())), nil
+		}
+	}
+
+	TabNewHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args tabNewArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			url := args.URL
+			if url != "" && !utils.IsHttp(url) {
+				log.Errorf("Invalid URL: %s", url)
+				return nil, invalidArgument("invalid URL")
+			}
+			tab, err := rodCtx.NewTab(ctx, url, args.Activate)
+			if err != nil {
+				log.Errorf("Failed to open new tab: %s", err.Error())
+				return nil, fail(err, 0, "Failed to open new tab")
+			}
+			if url != "" {
+				timeout := rodCtx.Config().TimeoutFor(request.Params.Name)
+				page := tab.Page.Context(ctx).Timeout(timeout)
+				err = page.WaitDOMStable(defaultWaitStableDur, defaultDomDiff)
+				page.CancelTimeout()
+				if err != nil {
+					log.Errorf("Failed to wait for %s in new tab %s: %s", url, tab.ID, reason(err, timeout))
+					return nil, fail(err, timeout, "Failed to wait for %s in new tab %s", url, tab.ID)
+				}
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Open new tab %s successfully", tab.ID)), nil
+		}
+	}
+
+	TabSelectHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args tabSelectArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			id := args.ID
+			tab, err := rodCtx.SelectTab(ctx, id)
+			if err != nil {
+				log.Errorf("Failed to switch to tab %s: %s", id, err.Error())
+				return nil, fail(err, 0, "Failed to switch to tab %s", id)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Switch to tab %s successfully\n%s", id, describeTabs(ctx, []*types.Tab{tab}, tab))), nil
+		}
+	}
+
+	TabCloseHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args tabCloseArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			id := args.ID
+			err := rodCtx.CloseTab(ctx, id)
+			if err != nil {
+				log.Errorf("Failed to close tab %s: %s", id, err.Error())
+				return nil, fail(err, 0, "Failed to close tab %s", id)
+			}
+			return mcp.NewToolResultText("Close tab successfully"), nil
+		}
+	}
+)
+
+// describeTabs lists the tabs with the title and URL read from the browser, the reads end with ctx
+func describeTabs(ctx context.Context, tabs []*types.Tab, active *types.Tab) string {
+	lines := make([]string, 0, len(tabs))
+	for _, tab := range tabs {
+		title, url := "", ""
+		if info, err := tab.Page.Context(ctx).Info(); err == nil {
+			title, url = info.Title, info.URL
+		}
+		marker := " "
+		if tab == active {
+			marker = "*"
+		}
+		lines = append(lines, fmt.Sprintf("%s id: %s, title: %q, url: %s", marker, tab.ID, title, url))
+	}
+	return strings.Join(lines, "\n")
+}
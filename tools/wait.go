@@ -0,0 +1,249 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod-mcp/types"
+	"github.com/mark3labs/mcp-go/mcp"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const waitPollInterval = 100 * time.Millisecond
+
+// elementStateJS checks the state of the first element matching the selector
+const elementStateJS = `(selector, state) => {
+	const el = document.querySelector(selector);
+	const visible = !!el && !!(el.offsetWidth || el.offsetHeight || el.getClientRects().length) &&
+		getComputedStyle(el).visibility !== 'hidden';
+	switch (state) {
+	case 'attached':
+		return !!el;
+	case 'detached':
+		return !el;
+	case 'visible':
+		return visible;
+	case 'hidden':
+		return !visible;
+	}
+	throw new Error('unsupported state ' + state);
+}`
+
+// textPresentJS checks the rendered text of the page
+const textPresentJS = `(text) => !!document.body && document.body.innerText.includes(text)`
+
+type waitForArgs struct {
+	Selector    string  `json:"selector" description:"CSS selector of the element to wait for"`
+	State       string  `json:"state" enum:"attached,visible,hidden,detached" default:"visible" description:"State of the selector element to wait for"`
+	Text        string  `json:"text" description:"Text to wait for on the page"`
+	URL         string  `json:"url" description:"Glob the page URL must match, '*' matches any characters except '/' and '**' matches any characters, such as '**/dashboard'"`
+	URLRegex    string  `json:"url_regex" description:"Regular expression the page URL must match"`
+	NetworkIdle float64 `json:"network_idle" min:"1" description:"Wait until the page has made no network request for this many milliseconds"`
+	Predicate   string  `json:"predicate" description:"JavaScript expression or function to wait for until it returns a truthy value, such as 'window.appReady === true'"`
+	Timeout     float64 `json:"timeout" min:"0" description:"Timeout in milliseconds, the default comes from the server configuration"`
+}
+
+var (
+	WaitFor = newTool("rod_wait_for", "Wait until the page reaches a condition, such as an element becoming visible after delayed rendering. All the given conditions are waited for in turn", waitForArgs{})
+)
+
+// waitCondition is a condition of rod_wait_for
+type waitCondition struct {
+	name string
+	wait func(page *rod.Page) error
+}
+
+var (
+	WaitForHandler = func(rodCtx *types.Context) func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			var args waitForArgs
+			if err := decodeArgs(request, &args); err != nil {
+				return nil, err
+			}
+			conditions, err := waitConditions(rodCtx, args)
+			if err != nil {
+				return nil, err
+			}
+			timeout := rodCtx.Config().TimeoutFor(request.Params.Name)
+			if args.Timeout > 0 {
+				timeout = time.Duration(args.Timeout) * time.Millisecond
+			}
+
+			page, err := currentPage(ctx, rodCtx)
+			if err != nil {
+				log.Errorf("Failed to wait: %s", err.Error())
+				return nil, fail(err, 0, "Failed to wait")
+			}
+			page = page.Context(ctx).Timeout(timeout)
+			defer page.CancelTimeout()
+
+			start := time.Now()
+			for _, condition := range conditions {
+				if err := condition.wait(page); err != nil {
+					if errors.Is(err, context.DeadlineExceeded) {
+						return nil, newToolError(CodeTimeout, "Timeout %s exceeded while waiting for %s", timeout, condition.name)
+					}
+					log.Errorf("Failed to wait for %s: %s", condition.name, err.Error())
+					return nil, fail(err, 0, "Failed to wait for %s", condition.name)
+				}
+			}
+			names := make([]string, 0, len(conditions))
+			for _, condition := range conditions {
+				names = append(names, condition.name)
+			}
+			return mcp.NewToolResultText(fmt.Sprintf("Wait for %s successfully in %dms", strings.Join(names, ", "), time.Since(start).Milliseconds())), nil
+		}
+	}
+)
+
+func waitConditions(rodCtx *types.Context, args waitForArgs) ([]waitCondition, error) {
+	var conditions []waitCondition
+	if selector := args.Selector; selector != "" {
+		state := args.State
+		conditions = append(conditions, waitCondition{
+			name: fmt.Sprintf("element %s to be %s", selector, state),
+			wait: func(page *rod.Page) error {
+				return waitJS(page, elementStateJS, selector, state)
+			},
+		})
+	}
+	if text := args.Text; text != "" {
+		conditions = append(conditions, waitCondition{
+			name: fmt.Sprintf("text %q", text),
+			wait: func(page *rod.Page) error {
+				return waitJS(page, textPresentJS, text)
+			},
+		})
+	}
+	if glob := args.URL; glob != "" {
+		re := globRegexp(glob)
+		conditions = append(conditions, waitCondition{
+			name: fmt.Sprintf("URL %s", glob),
+			wait: func(page *rod.Page) error {
+				return waitURL(page, re)
+			},
+		})
+	}
+	if pattern := args.URLRegex; pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, invalidArgument("Invalid url_regex %s: %s", pattern, err.Error())
+		}
+		conditions = append(conditions, waitCondition{
+			name: fmt.Sprintf("URL matching %s", pattern),
+			wait: func(page *rod.Page) error {
+				return waitURL(page, re)
+			},
+		})
+	}
+	if predicate := strings.TrimSpace(args.Predicate); predicate != "" {
+		if rodCtx.Config().DisableEvaluate {
+			return nil, newToolError(CodeDisabled, "predicate is disabled by the server configuration")
+		}
+		if !functionExprRegexp.MatchString(predicate) {
+			predicate = fmt.Sprintf("() => (%s)", predicate)
+		}
+		conditions = append(conditions, waitCondition{
+			name: "predicate",
+			wait: func(page *rod.Page) error {
+				return waitJS(page, predicate)
+			},
+		})
+	}
+	if args.NetworkIdle > 0 {
+		d := time.Duration(args.NetworkIdle) * time.Millisecond
+		conditions = append(conditions, waitCondition{
+			name: fmt.Sprintf("network idle for %s", d),
+			wait: func(page *rod.Page) error {
+				return waitNetworkIdle(page, d)
+			},
+		})
+	}
+	if len(conditions) == 0 {
+		return nil, invalidArgument("one of selector, text, url, url_regex, network_idle or predicate is required")
+	}
+	return conditions, nil
+}
+
+// poll calls check until it is true or the context of the page is done, the errors of check are
+// retried because the page may be navigating, the last one is reported on timeout
+func poll(page *rod.Page, check func() (bool, error)) error {
+	ctx := page.GetContext()
+	ticker := time.NewTicker(waitPollInterval)
+	defer ticker.Stop()
+	var lastErr error
+	for {
+		ok, err := check()
+		if ok {
+			return nil
+		}
+		if err != nil {
+			lastErr = err
+		}
+		select {
+		case <-ctx.Done():
+			if lastErr != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("%w, last error: %s", ctx.Err(), lastErr)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitJS waits until the function returns a truthy value or a promise resolving to one
+func waitJS(page *rod.Page, js string, args ...interface{}) error {
+	truthy := fmt.Sprintf("async function (...args) { return !!(await (%s).apply(this, args)) }", js)
+	return poll(page, func() (bool, error) {
+		res, err := page.Eval(truthy, args...)
+		if err != nil {
+			return false, err
+		}
+		return res.Value.Bool(), nil
+	})
+}
+
+func waitURL(page *rod.Page, re *regexp.Regexp) error {
+	return poll(page, func() (bool, error) {
+		info, err := page.Info()
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(info.URL), nil
+	})
+}
+
+// waitNetworkIdle waits until the page makes no request for the duration,
+// the requests started before the call are not waited for
+func waitNetworkIdle(page *rod.Page, d time.Duration) error {
+	page.WaitRequestIdle(d, nil, nil, nil)()
+	return page.GetContext().Err()
+}
+
+// globRegexp converts a URL glob to a regular expression, `**` matches any characters,
+// `*` any characters except `/` and `?` a single character
+func globRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
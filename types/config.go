@@ -7,36 +7,165 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 const ConfigName = "rod-mcp.yaml"
 
+// Available transports of the MCP server
+const (
+	TransportStdio          = "stdio"
+	TransportSSE            = "sse"
+	TransportStreamableHTTP = "streamable-http"
+)
+
+// Strategies to decide a navigation is done
+const (
+	WaitUntilLoad             = "load"
+	WaitUntilDOMContentLoaded = "domcontentloaded"
+	WaitUntilNetworkIdle      = "network-idle"
+	WaitUntilDOMStable        = "dom-stable"
+	WaitUntilNone             = "none"
+)
+
 type Config struct {
-	ServerName     string       `yaml:"serverName" json:"serverName"`
-	ServerVersion  string       `yaml:"-" json:"-"`
-	BrowserBinPath string       `yaml:"browserBinPath" json:"browserBinPath"`
-	Headless       bool         `yaml:"headless" json:"headless"`
-	BrowserTempDir string       `yaml:"browserTempDir" json:"browserTempDir"`
-	NoSandbox      bool         `yaml:"noSandbox" json:"noSandbox"`
-	Proxy          string       `yaml:"proxy" json:"proxy"`
-	LoggerConfig   LoggerConfig `yaml:"loggerConfig" json:"loggerConfig"`
+	ServerName            string                   `yaml:"serverName" json:"serverName"`
+	ServerVersion         string                   `yaml:"-" json:"-"`
+	BrowserBinPath        string                   `yaml:"browserBinPath" json:"browserBinPath"`
+	Headless              bool                     `yaml:"headless" json:"headless"`
+	BrowserTempDir        string                   `yaml:"browserTempDir" json:"browserTempDir"`
+	NoSandbox             bool                     `yaml:"noSandbox" json:"noSandbox"`
+	Proxy                 string                   `yaml:"proxy" json:"proxy"`
+	Profile               string                   `yaml:"profile" json:"profile"`
+	Profiles              []BrowserProfile         `yaml:"profiles" json:"profiles"`
+	RemoteDebuggingURL    string                   `yaml:"remoteDebuggingURL" json:"remoteDebuggingURL"`
+	AdoptExistingTabs     bool                     `yaml:"adoptExistingTabs" json:"adoptExistingTabs"`
+	StorageStatePath      string                   `yaml:"storageStatePath" json:"storageStatePath"`
+	RestoreAfterCrash     bool                     `yaml:"restoreAfterCrash" json:"restoreAfterCrash"`
+	BrowserIdleTimeout    time.Duration            `yaml:"browserIdleTimeout" json:"browserIdleTimeout"`
+	BrowserMaxLifetime    time.Duration            `yaml:"browserMaxLifetime" json:"browserMaxLifetime"`
+	NavigationWaitUntil   string                   `yaml:"navigationWaitUntil" json:"navigationWaitUntil"`
+	NavigationTimeout     time.Duration            `yaml:"navigationTimeout" json:"navigationTimeout"`
+	ToolTimeout           time.Duration            `yaml:"toolTimeout" json:"toolTimeout"`
+	ToolTimeouts          map[string]time.Duration `yaml:"toolTimeouts" json:"toolTimeouts"`
+	ArtifactsDir          string                   `yaml:"artifactsDir" json:"artifactsDir"`
+	DisableEvaluate       bool                     `yaml:"disableEvaluate" json:"disableEvaluate"`
+	ConsoleBufferSize     int                      `yaml:"consoleBufferSize" json:"consoleBufferSize"`
+	NetworkBufferSize     int                      `yaml:"networkBufferSize" json:"networkBufferSize"`
+	NetworkCaptureHeaders bool                     `yaml:"networkCaptureHeaders" json:"networkCaptureHeaders"`
+	NetworkCaptureBodies  bool                     `yaml:"networkCaptureBodies" json:"networkCaptureBodies"`
+	NetworkMaxBodySize    int                      `yaml:"networkMaxBodySize" json:"networkMaxBodySize"`
+	Transport             string                   `yaml:"transport" json:"transport"`
+	ListenAddr            string                   `yaml:"listenAddr" json:"listenAddr"`
+	BasePath              string                   `yaml:"basePath" json:"basePath"`
+	SessionIdleTimeout    time.Duration            `yaml:"sessionIdleTimeout" json:"sessionIdleTimeout"`
+	AllowedOrigins        []string                 `yaml:"allowedOrigins" json:"allowedOrigins"`
+	LoggerConfig          LoggerConfig             `yaml:"loggerConfig" json:"loggerConfig"`
 }
 
 var (
-	DefaultBrowserTempDir = "./rod/browser"
-	DefaultServerName     = "Rod Server"
+	DefaultBrowserTempDir      = "./rod/browser"
+	DefaultServerName          = "Rod Server"
+	DefaultArtifactsDir        = "./rod/artifacts"
+	DefaultConsoleBufferSize   = 1000
+	DefaultNetworkBufferSize   = 500
+	DefaultNetworkMaxBodySize  = 64 * 1024
+	DefaultTransport           = TransportStdio
+	DefaultListenAddr          = "localhost:8080"
+	DefaultSessionIdleTimeout  = 30 * time.Minute
+	DefaultNavigationWaitUntil = WaitUntilDOMStable
+	DefaultNavigationTimeout   = 30 * time.Second
+	DefaultToolTimeout         = 30 * time.Second
 
 	DefaultConfig = Config{
-		BrowserBinPath: "",
-		Headless:       false,
-		BrowserTempDir: DefaultBrowserTempDir,
-		NoSandbox:      false,
-		Proxy:          "",
-		ServerName:     DefaultServerName,
-		LoggerConfig:   DefaultLoggerConfig,
+		BrowserBinPath:        "",
+		Headless:              false,
+		BrowserTempDir:        DefaultBrowserTempDir,
+		NoSandbox:             false,
+		Proxy:                 "",
+		Profile:               "",
+		Profiles:              []BrowserProfile{},
+		RemoteDebuggingURL:    "",
+		AdoptExistingTabs:     false,
+		StorageStatePath:      "",
+		RestoreAfterCrash:     false,
+		BrowserIdleTimeout:    0,
+		BrowserMaxLifetime:    0,
+		NavigationWaitUntil:   DefaultNavigationWaitUntil,
+		NavigationTimeout:     DefaultNavigationTimeout,
+		ToolTimeout:           DefaultToolTimeout,
+		ToolTimeouts:          map[string]time.Duration{},
+		ArtifactsDir:          DefaultArtifactsDir,
+		DisableEvaluate:       false,
+		ConsoleBufferSize:     DefaultConsoleBufferSize,
+		NetworkBufferSize:     DefaultNetworkBufferSize,
+		NetworkCaptureHeaders: false,
+		NetworkCaptureBodies:  false,
+		NetworkMaxBodySize:    DefaultNetworkMaxBodySize,
+		Transport:             DefaultTransport,
+		ListenAddr:            DefaultListenAddr,
+		BasePath:              "",
+		SessionIdleTimeout:    DefaultSessionIdleTimeout,
+		AllowedOrigins:        []string{},
+		ServerName:            DefaultServerName,
+		LoggerConfig:          DefaultLoggerConfig,
 	}
 )
 
+// TimeoutFor returns the timeout of the tool, toolTimeouts overrides toolTimeout for single tools
+func (cfg Config) TimeoutFor(tool string) time.Duration {
+	if timeout, ok := cfg.ToolTimeouts[tool]; ok && timeout > 0 {
+		return timeout
+	}
+	if cfg.ToolTimeout > 0 {
+		return cfg.ToolTimeout
+	}
+	return DefaultToolTimeout
+}
+
+// navigationTimeout returns the timeout of navigations, the default when it is not set
+func (cfg Config) navigationTimeout() time.Duration {
+	if cfg.NavigationTimeout > 0 {
+		return cfg.NavigationTimeout
+	}
+	return DefaultNavigationTimeout
+}
+
+// sharesBrowser reports whether the client sessions of the transport share one browser, each in its
+// own incognito context
+func (cfg Config) sharesBrowser() bool {
+	return cfg.Transport == TransportSSE || cfg.Transport == TransportStreamableHTTP
+}
+
+// waitUntils are the strategies navigationWaitUntil accepts
+var waitUntils = []string{WaitUntilLoad, WaitUntilDOMContentLoaded, WaitUntilNetworkIdle, WaitUntilDOMStable, WaitUntilNone}
+
+// validateFile checks the options which are invalid whatever the command line overrides
+func (cfg Config) validateFile() error {
+	if waitUntil := cfg.NavigationWaitUntil; waitUntil != "" {
+		for _, valid := range waitUntils {
+			if waitUntil == valid {
+				return nil
+			}
+		}
+		return errors.Errorf("navigationWaitUntil must be one of %s, got %q", strings.Join(waitUntils, ", "), waitUntil)
+	}
+	return nil
+}
+
+// Validate checks the options which are invalid or can not be used together
+func (cfg Config) Validate() error {
+	if err := cfg.validateFile(); err != nil {
+		return err
+	}
+	if cfg.sharesBrowser() && cfg.RemoteDebuggingURL == "" {
+		if _, err := cfg.pooledProfile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // InitDefaultConfig Generate the default configuration file
 func InitDefaultConfig() error {
 
@@ -93,6 +222,9 @@ func LoadConfig(configPath string) (*Config, error) {
 			if err := decoder.Decode(&config); err != nil {
 				return nil, err
 			}
+			if err := config.validateFile(); err != nil {
+				return nil, errors.Wrapf(err, "invalid config file %s", configPath)
+			}
 			return &config, nil
 		}
 		return nil, errors.Wrapf(err, "config file name is wrong")
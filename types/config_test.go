@@ -0,0 +1,65 @@
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigValidate(t *testing.T) {
+	profiles := []BrowserProfile{
+		{Name: "work", UserDataDir: "/data/work"},
+		{Name: "clean", Ephemeral: true},
+	}
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{name: "stdio with a profile", cfg: Config{Transport: TransportStdio, Profile: "work", Profiles: profiles}},
+		{name: "sse without a profile", cfg: Config{Transport: TransportSSE}},
+		{name: "sse with an ephemeral profile", cfg: Config{Transport: TransportSSE, Profile: "clean", Profiles: profiles}},
+		{name: "remote browser", cfg: Config{Transport: TransportSSE, Profile: "work", Profiles: profiles, RemoteDebuggingURL: "localhost:9222"}},
+		{
+			name:    "sse with a profile",
+			cfg:     Config{Transport: TransportSSE, Profile: "work", Profiles: profiles},
+			wantErr: "profile work keeps its data in /data/work which the incognito contexts of the sse transport can not use, use the stdio transport or an ephemeral profile",
+		},
+		{name: "navigation wait", cfg: Config{NavigationWaitUntil: WaitUntilNetworkIdle}},
+		{
+			name:    "unknown navigation wait",
+			cfg:     Config{NavigationWaitUntil: "idle"},
+			wantErr: `navigationWaitUntil must be one of load, domcontentloaded, network-idle, dom-stable, none, got "idle"`,
+		},
+		{
+			name:    "unknown profile",
+			cfg:     Config{Transport: TransportStreamableHTTP, Profile: "home", Profiles: profiles},
+			wantErr: "profile home not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Validate() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigName)
+	if err := os.WriteFile(path, []byte("navigationWaitUntil: idle\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want := "invalid config file " + path + `: navigationWaitUntil must be one of load, domcontentloaded, network-idle, dom-stable, none, got "idle"`
+	if _, err := LoadConfig(path); err == nil || err.Error() != want {
+		t.Errorf("LoadConfig() error = %v, want %s", err, want)
+	}
+}
@@ -0,0 +1,140 @@
+package types
+
+import (
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"strings"
+	"time"
+)
+
+const ConsoleLevelException = "exception"
+
+// ConsoleMessage is a console API call or an uncaught exception of a page
+type ConsoleMessage struct {
+	PageID    string    `json:"pageId"`
+	Level     string    `json:"level"`
+	Text      string    `json:"text"`
+	URL       string    `json:"url,omitempty"`
+	Line      int       `json:"line,omitempty"`
+	Column    int       `json:"column,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (m ConsoleMessage) String() string {
+	location := ""
+	if m.URL != "" {
+		location = fmt.Sprintf(" (%s:%d:%d)", m.URL, m.Line, m.Column)
+	}
+	return fmt.Sprintf("[%s] [%s] %s%s", m.Timestamp.Format(time.RFC3339Nano), m.Level, m.Text, location)
+}
+
+// ConsoleFilter selects console messages, zero fields match everything
+type ConsoleFilter struct {
+	Levels []string
+	Since  time.Time
+	PageID string
+}
+
+func (f ConsoleFilter) match(m ConsoleMessage) bool {
+	if f.PageID != "" && m.PageID != f.PageID {
+		return false
+	}
+	if !f.Since.IsZero() && m.Timestamp.Before(f.Since) {
+		return false
+	}
+	if len(f.Levels) == 0 {
+		return true
+	}
+	for _, level := range f.Levels {
+		if strings.EqualFold(level, m.Level) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConsoleLogs is a bounded buffer of the console messages of all pages in a context
+type ConsoleLogs struct {
+	buffer *ringBuffer[ConsoleMessage]
+}
+
+func NewConsoleLogs(size int) *ConsoleLogs {
+	return &ConsoleLogs{buffer: newRingBuffer[ConsoleMessage](size)}
+}
+
+func (c *ConsoleLogs) Add(m ConsoleMessage) {
+	c.buffer.push(m)
+}
+
+func (c *ConsoleLogs) List(filter ConsoleFilter) []ConsoleMessage {
+	return c.buffer.list(filter.match)
+}
+
+// Drain lists the messages matching the filter and clears all the messages atomically
+func (c *ConsoleLogs) Drain(filter ConsoleFilter) []ConsoleMessage {
+	return c.buffer.drain(filter.match)
+}
+
+func (c *ConsoleLogs) Clear() {
+	c.buffer.clear()
+}
+
+// watchConsole collects the console output of the page until the page context is done
+func watchConsole(page *rod.Page, logs *ConsoleLogs) {
+	pageID := string(page.TargetID)
+	wait := page.EachEvent(func(e *proto.RuntimeConsoleAPICalled) {
+		texts := make([]string, 0, len(e.Args))
+		for _, arg := range e.Args {
+			texts = append(texts, remoteObjectText(arg))
+		}
+		m := ConsoleMessage{
+			PageID:    pageID,
+			Level:     string(e.Type),
+			Text:      strings.Join(texts, " "),
+			Timestamp: runtimeTime(e.Timestamp),
+		}
+		if e.StackTrace != nil && len(e.StackTrace.CallFrames) > 0 {
+			frame := e.StackTrace.CallFrames[0]
+			m.URL, m.Line, m.Column = frame.URL, frame.LineNumber+1, frame.ColumnNumber+1
+		}
+		logs.Add(m)
+	}, func(e *proto.RuntimeExceptionThrown) {
+		details := e.ExceptionDetails
+		m := ConsoleMessage{
+			PageID:    pageID,
+			Level:     ConsoleLevelException,
+			Text:      details.Text,
+			URL:       details.URL,
+			Line:      details.LineNumber + 1,
+			Column:    details.ColumnNumber + 1,
+			Timestamp: runtimeTime(e.Timestamp),
+		}
+		if details.Exception != nil && details.Exception.Description != "" {
+			m.Text = details.Exception.Description
+		}
+		logs.Add(m)
+	})
+	go wait()
+}
+
+func remoteObjectText(obj *proto.RuntimeRemoteObject) string {
+	if obj.Type == proto.RuntimeRemoteObjectTypeString {
+		return obj.Value.Str()
+	}
+	if obj.UnserializableValue != "" {
+		return string(obj.UnserializableValue)
+	}
+	if obj.Type == proto.RuntimeRemoteObjectTypeUndefined {
+		return "undefined"
+	}
+	if obj.Description != "" {
+		return obj.Description
+	}
+	return obj.Value.JSON("", "")
+}
+
+// runtimeTime converts the milliseconds since epoch of the Runtime domain
+func runtimeTime(t proto.RuntimeTimestamp) time.Time {
+	return time.UnixMicro(int64(float64(t) * 1000))
+}
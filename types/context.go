@@ -2,27 +2,34 @@ package types
 
 import (
 	"context"
-	"fmt"
 	"github.com/go-rod/rod"
-	"github.com/go-rod/rod-mcp/utils"
 	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
 	"github.com/go-rod/rod/lib/proto"
 	"github.com/pkg/errors"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
-func launchBrowser(ctx context.Context, cfg Config) (*rod.Browser, error) {
-	if cfg.BrowserTempDir == "" {
-		cfg.BrowserTempDir = DefaultBrowserTempDir
-	}
+// browserExitWait is how long a browser closed through the protocol gets to exit on its own before its
+// process is killed, so that a persistent profile is written completely
+const browserExitWait = 5 * time.Second
 
-	// browser must own a unique temp dir
-	cfg.BrowserTempDir = fmt.Sprintf("%s/%s", cfg.BrowserTempDir, utils.RandomString(10))
+// launchBrowser launches a local browser with the profile, cleanup stops the process of the browser and
+// removes the user data dir of ephemeral profiles. closed tells cleanup that the browser was closed
+// through the protocol and is exiting on its own, otherwise the process is killed right away
+func launchBrowser(ctx context.Context, cfg Config, profile BrowserProfile) (browser *rod.Browser, cleanup func(closed bool), err error) {
+	userDataDir := profile.userDataDir(cfg)
+	if profile.Ephemeral {
+		if err := markTempDir(userDataDir); err != nil {
+			return nil, nil, err
+		}
+	}
 	browserLauncher := launcher.New().
 		Context(ctx).
-		Headless(cfg.Headless).
+		Headless(profile.headless(cfg)).
 		NoSandbox(cfg.NoSandbox).
 		Set("no-gpu").
 		Set("--no-first-run").
@@ -35,7 +42,15 @@ func launchBrowser(ctx context.Context, cfg Config) (*rod.Browser, error) {
 		Set("--remote-allow-origins", "*").
 		Set("--disable-dev-shm-usage").
 		Set("--disable-features", "HttpsUpgrades").
-		UserDataDir(cfg.BrowserTempDir)
+		UserDataDir(userDataDir)
+
+	for name, value := range profile.Flags {
+		if value == "" {
+			browserLauncher.Set(flags.Flag(name))
+		} else {
+			browserLauncher.Set(flags.Flag(name), value)
+		}
+	}
 
 	if cfg.BrowserBinPath != "" {
 		browserLauncher.Bin(cfg.BrowserBinPath)
@@ -43,120 +58,294 @@ func launchBrowser(ctx context.Context, cfg Config) (*rod.Browser, error) {
 		if browserPath, has := launcher.LookPath(); has {
 			browserLauncher.Bin(browserPath)
 		} else {
-			return nil, errors.New("the machine does not have Chrome installed,please set the executable_path or installed a chrome")
+			return nil, nil, errors.New("the machine does not have Chrome installed,please set the executable_path or installed a chrome")
 		}
 	}
 
-	if cfg.Proxy != "" {
-		browserLauncher.Proxy(cfg.Proxy)
+	if proxy := profile.proxy(cfg); proxy != "" {
+		browserLauncher.Proxy(proxy)
+	}
+
+	cleanup = func(closed bool) {
+		if closed {
+			waitBrowserExit(userDataDir, browserExitWait)
+		}
+		browserLauncher.Kill()
+		if profile.Ephemeral {
+			browserLauncher.Cleanup()
+		}
 	}
 
-	browser := rod.New().Context(ctx)
+	browser = rod.New().Context(ctx)
 
 	controlUrl, err := browserLauncher.Launch()
 	if err != nil {
-		return nil, errors.Wrap(err, "launch local browser failed")
+		return nil, nil, errors.Wrap(err, "launch local browser failed")
 	}
 
 	err = browser.ControlURL(controlUrl).Connect()
 	if err != nil {
+		defer cleanup(false)
 		err := browser.Close()
 		if err != nil {
-			return nil, errors.Wrap(err, "in connect local browser stage to close browser happened err")
+			return nil, nil, errors.Wrap(err, "in connect local browser stage to close browser happened err")
 		}
-		return nil, errors.Wrap(err, "Error connecting to local browser")
+		return nil, nil, errors.Wrap(err, "Error connecting to local browser")
 	}
-	return browser, nil
+	return browser, cleanup, nil
 }
 
+// closeBrowserLimit bounds closing the browser when no request waits for it
+const closeBrowserLimit = 10 * time.Second
+
 type Context struct {
-	stdContext context.Context
-	config     Config
-	browser    *rod.Browser
-	page       *rod.Page
-	stateLock  sync.Mutex
-	isInitial  atomic.Bool
+	stdContext  context.Context
+	config      Config
+	browser     *rod.Browser
+	disconnect  func() error
+	cleanup     func(closed bool)
+	profile     string
+	tabs        []*Tab
+	activeTab   *Tab
+	stopTargets context.CancelFunc
+	pool        *BrowserPool
+	shared      *sharedBrowser
+	consoleLogs *ConsoleLogs
+	networkLogs *NetworkLogs
+	loss        *browserLoss
+	recovery    string
+	restore     *pendingRestore
+	autoLoaded  []OriginStorage
+	launchedAt  time.Time
+	lastUsed    time.Time
+	busy        atomic.Int32
+	actions     actionQueue
+	stopWatch   context.CancelFunc
+	stateLock   sync.Mutex
+	isInitial   atomic.Bool
 }
 
 func NewContext(ctx context.Context, cfg Config) *Context {
-	return &Context{
-		stdContext: ctx,
-		config:     cfg,
+	consoleSize := cfg.ConsoleBufferSize
+	if consoleSize <= 0 {
+		consoleSize = DefaultConsoleBufferSize
+	}
+	networkSize := cfg.NetworkBufferSize
+	if networkSize <= 0 {
+		networkSize = DefaultNetworkBufferSize
+	}
+	maxBodySize := cfg.NetworkMaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultNetworkMaxBodySize
+	}
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	rodCtx := &Context{
+		stdContext:  ctx,
+		config:      cfg,
+		profile:     cfg.Profile,
+		stopWatch:   stopWatch,
+		consoleLogs: NewConsoleLogs(consoleSize),
+		networkLogs: NewNetworkLogs(networkSize, NetworkCaptureOptions{
+			Headers:     cfg.NetworkCaptureHeaders,
+			Bodies:      cfg.NetworkCaptureBodies,
+			MaxBodySize: maxBodySize,
+		}),
+	}
+	go rodCtx.watchLifecycle(watchCtx)
+	return rodCtx
+}
+
+// Config returns the configuration the context was created with
+func (ctx *Context) Config() Config {
+	return ctx.config
+}
+
+// ArtifactsDir returns the directory generated files such as PDFs and screenshots are written to
+func (ctx *Context) ArtifactsDir() string {
+	if ctx.config.ArtifactsDir == "" {
+		return DefaultArtifactsDir
 	}
+	return ctx.config.ArtifactsDir
+}
+
+// ConsoleLogs returns the console messages collected from the pages of the context
+func (ctx *Context) ConsoleLogs() *ConsoleLogs {
+	return ctx.consoleLogs
+}
+
+// NetworkLogs returns the network requests recorded from the pages of the context
+func (ctx *Context) NetworkLogs() *NetworkLogs {
+	return ctx.networkLogs
 }
 
+// EnsurePage returns the page of the active tab, the browser and the tab are created if needed
 func (ctx *Context) EnsurePage() (*rod.Page, error) {
 	if err := ctx.initial(); err != nil {
 		return nil, err
 	}
-	return ctx.page, nil
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	if ctx.activeTab == nil {
+		return nil, errors.New("no active tab")
+	}
+	return ctx.activeTab.Page, nil
 
 }
 
 func (ctx *Context) initial() error {
 	ctx.stateLock.Lock()
 	defer ctx.stateLock.Unlock()
+	return ctx.ready()
+}
 
+// readyBrowser returns the browser once it is ready, the calls on it should be bound to the request
+// as they run outside of the state lock
+func (ctx *Context) readyBrowser() (*rod.Browser, error) {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	if err := ctx.ready(); err != nil {
+		return nil, err
+	}
+	return ctx.browser, nil
+}
+
+// ready makes sure the browser and the active tab exist, the caller holds the state lock until it is
+// done with them so that they can not be closed meanwhile
+func (ctx *Context) ready() error {
+	ctx.lastUsed = time.Now()
+	return ctx.ensureBrowser(true)
+}
+
+// ensureBrowser launches the browser if needed, withTab also makes sure there is an active tab
+func (ctx *Context) ensureBrowser(withTab bool) error {
 	var err error
+	launched := false
 	if ctx.browser == nil {
-		ctx.browser, err = launchBrowser(ctx.stdContext, ctx.config)
-		if err != nil {
-			return err
+		switch {
+		case ctx.pool != nil:
+			ctx.browser, ctx.shared, err = ctx.pool.incognito()
+		case ctx.config.RemoteDebuggingURL != "":
+			ctx.browser, ctx.disconnect, err = connectBrowser(ctx.stdContext, ctx.config)
+		default:
+			var profile BrowserProfile
+			if profile, err = ctx.config.FindProfile(ctx.profile); err == nil {
+				ctx.browser, ctx.cleanup, err = launchBrowser(ctx.stdContext, ctx.config, profile)
+			}
 		}
-		ctx.page, err = ctx.createPage()
 		if err != nil {
 			return err
 		}
-		return nil
-
+		ctx.watchTargets()
+		if ctx.disconnect != nil && ctx.config.AdoptExistingTabs {
+			if err := ctx.adoptPages(); err != nil {
+				return err
+			}
+		}
+		launched = true
+		ctx.launchedAt = time.Now()
+		if ctx.shared != nil {
+			// the contexts of a pool recycle together with the shared browser
+			ctx.launchedAt = ctx.shared.launchedAt
+		}
 	}
-	if ctx.page == nil {
-		ctx.page, err = ctx.createPage()
+	// a crashed active tab is replaced by a new tab rather than by another open tab
+	if withTab && ctx.loss != nil && ctx.loss.tabOnly {
+		tab, err := ctx.createTab()
 		if err != nil {
 			return err
 		}
+		ctx.activeTab = tab
 	}
-
-	return err
-
+	if launched && ctx.config.StorageStatePath != "" {
+		if err := ctx.autoLoadStorageState(); err != nil {
+			return errors.Wrap(err, "load storage state failed")
+		}
+	}
+	if withTab && ctx.activeTab == nil {
+		if len(ctx.tabs) > 0 {
+			ctx.activeTab = ctx.tabs[len(ctx.tabs)-1]
+		} else {
+			tab, err := ctx.createTab()
+			if err != nil {
+				return err
+			}
+			ctx.activeTab = tab
+		}
+	}
+	if withTab && ctx.loss != nil {
+		ctx.recovery = ctx.recoverLoss(ctx.loss)
+		ctx.loss = nil
+	}
+	return nil
 }
 
+// ClosePage closes the active tab
 func (ctx *Context) ClosePage() error {
 	ctx.stateLock.Lock()
 	defer ctx.stateLock.Unlock()
 	return ctx.closePage()
 }
 
-func (ctx *Context) CloseBrowser() error {
+// CloseBrowser closes the browser, the calls closing it end with reqCtx
+func (ctx *Context) CloseBrowser(reqCtx context.Context) error {
 	ctx.stateLock.Lock()
 	defer ctx.stateLock.Unlock()
-	return ctx.closeBrowser()
+	return ctx.closeBrowser(reqCtx)
 
 }
 
 func (ctx *Context) closePage() error {
-	if ctx.page == nil {
+	if ctx.activeTab == nil {
 		return nil
 	}
-	err := ctx.page.Close()
-	if err != nil {
-		return errors.Wrap(err, "close page failed")
-	}
-	ctx.page = nil
-	return err
+	return ctx.closeTab(ctx.activeTab)
 }
-func (ctx *Context) closeBrowser() error {
 
-	err := ctx.closePage()
-	if err != nil {
-		return err
+// closeBrowser closes the browser with the calls bound to closeCtx, the state is reset even if a call failed
+func (ctx *Context) closeBrowser(closeCtx context.Context) error {
+
+	// a remote browser is left running, only the tabs opened by rod-mcp are closed
+	if ctx.disconnect != nil {
+		for _, tab := range ctx.tabs {
+			if !tab.external {
+				_ = tab.Page.Context(closeCtx).Close()
+			}
+		}
+	}
+
+	// the pages are closed together with the browser
+	for len(ctx.tabs) > 0 {
+		ctx.forgetTab(ctx.tabs[0])
 	}
 
 	if ctx.browser == nil {
 		return nil
 	}
+	if ctx.stopTargets != nil {
+		ctx.stopTargets()
+		ctx.stopTargets = nil
+	}
 
-	err = ctx.browser.Close()
+	if ctx.disconnect != nil {
+		err := ctx.disconnect()
+		ctx.browser = nil
+		ctx.disconnect = nil
+		if err != nil {
+			return errors.Wrap(err, "disconnect remote browser failed")
+		}
+		return nil
+	}
+
+	err := ctx.browser.Context(closeCtx).Close()
+	if ctx.cleanup != nil {
+		ctx.cleanup(err == nil)
+		ctx.cleanup = nil
+	}
+	if ctx.shared != nil {
+		// the incognito context is of no use anymore even if disposing it failed
+		ctx.browser = nil
+		ctx.leavePool()
+	}
 	if err != nil {
 		return errors.Wrap(err, "close browser failed")
 	}
@@ -164,6 +353,14 @@ func (ctx *Context) closeBrowser() error {
 	return nil
 }
 
+// leavePool gives the shared browser back to the pool once the incognito context is gone
+func (ctx *Context) leavePool() {
+	if ctx.shared != nil {
+		ctx.pool.leave(ctx.shared)
+		ctx.shared = nil
+	}
+}
+
 func (ctx *Context) createPage(urls ...string) (*rod.Page, error) {
 	page, err := ctx.browser.Page(proto.TargetCreateTarget{URL: strings.Join(urls, "/")})
 	if err != nil {
@@ -172,12 +369,69 @@ func (ctx *Context) createPage(urls ...string) (*rod.Page, error) {
 	return page, nil
 }
 
+// openPage opens a tab with the url in the browser, the page outlives reqCtx as its session is bound to
+// the browser while the caller stops waiting for the browser once reqCtx is done
+func openPage(reqCtx context.Context, browser *rod.Browser, url string) (*rod.Page, error) {
+	target, err := proto.TargetCreateTarget{
+		URL:              "about:blank",
+		BrowserContextID: browser.BrowserContextID,
+	}.Call(browser.Context(reqCtx))
+	if err != nil {
+		return nil, errors.Wrap(err, "create page failed")
+	}
+	type attached struct {
+		page *rod.Page
+		err  error
+	}
+	done := make(chan attached, 1)
+	go func() {
+		page, err := browser.PageFromTarget(target.TargetID)
+		done <- attached{page, err}
+	}()
+	var page *rod.Page
+	select {
+	case res := <-done:
+		page, err = res.page, res.err
+	case <-reqCtx.Done():
+		err = reqCtx.Err()
+		go func() {
+			if res := <-done; res.err == nil {
+				_ = res.page.Close()
+			}
+		}()
+	}
+	if err == nil && url != "" {
+		err = page.Context(reqCtx).Navigate(url)
+	}
+	if err != nil {
+		// do not leave the target behind, the request may be done already
+		_, _ = proto.TargetCloseTarget{TargetID: target.TargetID}.Call(browser.Timeout(closeBrowserLimit))
+		return nil, errors.Wrap(err, "create page failed")
+	}
+	return page, nil
+}
+
+// watchTab subscribes the page events of the tab, the subscriptions end when stop is called
+func (ctx *Context) watchTab(tab *Tab) (stop context.CancelFunc) {
+	eventCtx, cancel := context.WithCancel(ctx.stdContext)
+	page := tab.Page.Context(eventCtx)
+	watchConsole(page, ctx.consoleLogs)
+	watchNetwork(page, ctx.networkLogs)
+	watchNavigation(page, tab.Refs)
+	watchURL(page, tab)
+	return cancel
+}
+
 // Close the browser
 // PS: This method only used because of server exit
 func (ctx *Context) Close() error {
+	ctx.stopWatch()
 	ctx.stateLock.Lock()
 	defer ctx.stateLock.Unlock()
-	ctx.closeBrowser()
+	// the server context may be done already, the browser is still closed
+	closeCtx, cancel := context.WithTimeout(context.Background(), closeBrowserLimit)
+	defer cancel()
+	ctx.closeBrowser(closeCtx)
 	return nil
 
 }
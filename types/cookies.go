@@ -0,0 +1,164 @@
+package types
+
+import (
+	"bufio"
+	"context"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// netscapeHTTPOnlyPrefix marks the http only cookies of a cookies.txt file
+const netscapeHTTPOnlyPrefix = "#HttpOnly_"
+
+// CookieFilter selects cookies, empty fields match every cookie
+type CookieFilter struct {
+	// Domain matches the cookies of the domain and its subdomains
+	Domain string
+	Name   string
+}
+
+func (f CookieFilter) match(c StorageCookie) bool {
+	if f.Name != "" && c.Name != f.Name {
+		return false
+	}
+	if f.Domain != "" {
+		domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+		want := strings.ToLower(strings.TrimPrefix(f.Domain, "."))
+		if domain != want && !strings.HasSuffix(domain, "."+want) {
+			return false
+		}
+	}
+	return true
+}
+
+// Cookies returns the cookies of the browser matching the filter, the browser is read outside of the
+// state lock and the read ends with reqCtx
+func (ctx *Context) Cookies(reqCtx context.Context, filter CookieFilter) ([]StorageCookie, error) {
+	browser, err := ctx.readyBrowser()
+	if err != nil {
+		return nil, err
+	}
+	return cookiesOf(browser.Context(reqCtx), filter)
+}
+
+func cookiesOf(browser *rod.Browser, filter CookieFilter) ([]StorageCookie, error) {
+	cookies, err := browser.GetCookies()
+	if err != nil {
+		return nil, errors.Wrap(err, "get cookies failed")
+	}
+	result := make([]StorageCookie, 0, len(cookies))
+	for _, c := range cookies {
+		if cookie := storageCookie(c); filter.match(cookie) {
+			result = append(result, cookie)
+		}
+	}
+	return result, nil
+}
+
+// SetCookies adds the cookies to the browser, a cookie with the same name, domain and path is replaced,
+// url scopes the cookies without a domain and sets the defaults of the path and secure of the others
+func (ctx *Context) SetCookies(reqCtx context.Context, cookies []StorageCookie, url string) error {
+	browser, err := ctx.readyBrowser()
+	if err != nil {
+		return err
+	}
+	params := make([]*proto.NetworkCookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		param := cookieParam(c)
+		param.URL = url
+		params = append(params, param)
+	}
+	if err := browser.Context(reqCtx).SetCookies(params); err != nil {
+		return errors.Wrap(err, "set cookies failed")
+	}
+	return nil
+}
+
+// DeleteCookies deletes the cookies matching the filter and returns how many were deleted
+func (ctx *Context) DeleteCookies(reqCtx context.Context, filter CookieFilter) (int, error) {
+	ctx.stateLock.Lock()
+	if err := ctx.ready(); err != nil {
+		ctx.stateLock.Unlock()
+		return 0, err
+	}
+	browser, page := ctx.browser, ctx.activeTab.Page
+	ctx.stateLock.Unlock()
+
+	cookies, err := cookiesOf(browser.Context(reqCtx), filter)
+	if err != nil {
+		return 0, err
+	}
+	page = page.Context(reqCtx)
+	for i, c := range cookies {
+		err := proto.NetworkDeleteCookies{Name: c.Name, Domain: c.Domain, Path: c.Path}.Call(page)
+		if err != nil {
+			return i, errors.Wrapf(err, "delete cookie %s of %s failed", c.Name, c.Domain)
+		}
+	}
+	return len(cookies), nil
+}
+
+// ClearCookies deletes all the cookies of the browser
+func (ctx *Context) ClearCookies(reqCtx context.Context) error {
+	browser, err := ctx.readyBrowser()
+	if err != nil {
+		return err
+	}
+	if err := browser.Context(reqCtx).SetCookies(nil); err != nil {
+		return errors.Wrap(err, "clear cookies failed")
+	}
+	return nil
+}
+
+// ParseNetscapeCookies parses a cookies.txt file in the Netscape format exported by browsers and curl,
+// the lines hold tab separated domain, include subdomains, path, secure, expires, name and value
+func ParseNetscapeCookies(r io.Reader) ([]StorageCookie, error) {
+	var cookies []StorageCookie
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		httpOnly := false
+		if strings.HasPrefix(line, netscapeHTTPOnlyPrefix) {
+			httpOnly = true
+			line = strings.TrimPrefix(line, netscapeHTTPOnlyPrefix)
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, errors.Errorf("line %d: expect 7 tab separated fields, got %d", lineNo, len(fields))
+		}
+		expires, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil {
+			return nil, errors.Errorf("line %d: invalid expires %s", lineNo, fields[4])
+		}
+		if expires <= 0 {
+			expires = -1
+		}
+		domain := fields[0]
+		if strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(domain, ".") {
+			domain = "." + domain
+		}
+		cookies = append(cookies, StorageCookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Domain:   domain,
+			Path:     fields[2],
+			Expires:  expires,
+			HTTPOnly: httpOnly,
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "read cookies file failed")
+	}
+	return cookies, nil
+}
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseNetscapeCookies(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []StorageCookie
+		wantErr string
+	}{
+		{
+			name: "cookies",
+			input: "# Netscape HTTP Cookie File\n" +
+				"\n" +
+				"example.com\tFALSE\t/\tFALSE\t1700000000\tsid\tabc\n" +
+				"example.com\tTRUE\t/app\tTRUE\t0\tpref\tdark mode\r\n" +
+				"#HttpOnly_.example.org\tTRUE\t/\tTRUE\t1700000000.5\ttoken\tx=y\n",
+			want: []StorageCookie{
+				{Name: "sid", Value: "abc", Domain: "example.com", Path: "/", Expires: 1700000000},
+				{Name: "pref", Value: "dark mode", Domain: ".example.com", Path: "/app", Expires: -1, Secure: true},
+				{Name: "token", Value: "x=y", Domain: ".example.org", Path: "/", Expires: 1700000000.5, HTTPOnly: true, Secure: true},
+			},
+		},
+		{
+			name:  "empty value",
+			input: "example.com\tFALSE\t/\tfalse\t-1\tempty\t\n",
+			want:  []StorageCookie{{Name: "empty", Domain: "example.com", Path: "/", Expires: -1}},
+		},
+		{
+			name:  "only comments",
+			input: "# comment\n\n",
+		},
+		{
+			name:    "missing fields",
+			input:   "# comment\nexample.com\tFALSE\t/\tFALSE\t0\tsid\n",
+			wantErr: "line 2: expect 7 tab separated fields, got 6",
+		},
+		{
+			name:    "invalid expires",
+			input:   "example.com\tFALSE\t/\tFALSE\tnever\tsid\tabc\n",
+			wantErr: "line 1: invalid expires never",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNetscapeCookies(strings.NewReader(tt.input))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseNetscapeCookies() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseNetscapeCookies() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseNetscapeCookies() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCookieFilterMatch(t *testing.T) {
+	cookie := StorageCookie{Name: "sid", Domain: ".Shop.Example.com"}
+	tests := []struct {
+		name   string
+		filter CookieFilter
+		want   bool
+	}{
+		{name: "empty filter", filter: CookieFilter{}, want: true},
+		{name: "same domain", filter: CookieFilter{Domain: "shop.example.com"}, want: true},
+		{name: "parent domain", filter: CookieFilter{Domain: ".example.com"}, want: true},
+		{name: "other domain", filter: CookieFilter{Domain: "ample.com"}, want: false},
+		{name: "subdomain", filter: CookieFilter{Domain: "eu.shop.example.com"}, want: false},
+		{name: "name", filter: CookieFilter{Name: "sid", Domain: "example.com"}, want: true},
+		{name: "other name", filter: CookieFilter{Name: "SID"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.match(cookie); got != tt.want {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
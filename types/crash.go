@@ -0,0 +1,178 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"os"
+	"strings"
+)
+
+// browserLoss is why the browser or the active tab was lost, the next call needing a page relaunches
+// them and the loss is reported once through TakeRecovery
+type browserLoss struct {
+	reason string
+	// url of the active tab when it was lost
+	url string
+	// tabOnly is set when only the active tab crashed and the browser is still running
+	tabOnly bool
+}
+
+// TakeRecovery returns the note about the last relaunch after the browser or the active tab was lost,
+// the note is cleared so that it is reported only once
+func (ctx *Context) TakeRecovery() string {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	note := ctx.recovery
+	ctx.recovery = ""
+	return note
+}
+
+// URL returns the last URL the main frame of the tab navigated to
+func (t *Tab) URL() string {
+	url, _ := t.url.Load().(string)
+	return url
+}
+
+// watchURL keeps the URL of the tab so that it can be restored after a crash
+func watchURL(page *rod.Page, tab *Tab) {
+	wait := page.EachEvent(func(e *proto.PageFrameNavigated) {
+		if e.Frame.ParentID == "" {
+			tab.url.Store(e.Frame.URL)
+		}
+	})
+	go wait()
+}
+
+// browserLost forgets the browser whose connection is gone, such as when it crashed or its window was
+// closed, so that the next call relaunches it instead of failing on the stale browser
+func (ctx *Context) browserLost(browser *rod.Browser, reason string) {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	if ctx.browser != browser {
+		return
+	}
+	log.Warnf("Browser lost: %s", reason)
+	loss := &browserLoss{reason: reason}
+	if ctx.activeTab != nil {
+		loss.url = ctx.activeTab.URL()
+	}
+	for len(ctx.tabs) > 0 {
+		ctx.forgetTab(ctx.tabs[0])
+	}
+	if ctx.stopTargets != nil {
+		ctx.stopTargets()
+		ctx.stopTargets = nil
+	}
+	if ctx.disconnect != nil {
+		_ = ctx.disconnect()
+		ctx.disconnect = nil
+	}
+	if ctx.cleanup != nil {
+		// the process may still run without answering, such as after its window was closed, kill it so
+		// that it neither leaks nor holds the profile the relaunch needs
+		ctx.cleanup(false)
+		ctx.cleanup = nil
+	}
+	ctx.browser = nil
+	ctx.leavePool()
+	ctx.loss = loss
+}
+
+// tabCrashed forgets the crashed tab, a crash of the active tab is recovered by the next call
+func (ctx *Context) tabCrashed(browser *rod.Browser, targetID proto.TargetTargetID, status string) {
+	tab := ctx.forgetCrashedTab(browser, targetID, status)
+	if tab != nil {
+		// the crashed target may not answer, do not hold the lock while closing it
+		_ = tab.Page.Close()
+	}
+}
+
+func (ctx *Context) forgetCrashedTab(browser *rod.Browser, targetID proto.TargetTargetID, status string) *Tab {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	if ctx.browser != browser {
+		return nil
+	}
+	tab := ctx.findTab(string(targetID))
+	if tab == nil {
+		return nil
+	}
+	log.Warnf("Tab %s crashed: %s", tab.ID, status)
+	if tab == ctx.activeTab {
+		ctx.loss = &browserLoss{
+			reason:  fmt.Sprintf("tab %s crashed (%s)", tab.ID, status),
+			url:     tab.URL(),
+			tabOnly: true,
+		}
+	}
+	ctx.forgetTab(tab)
+	return tab
+}
+
+// pendingRestore is the URL of a lost tab to reopen in the tab replacing it
+type pendingRestore struct {
+	tab *Tab
+	url string
+}
+
+// recoverLoss returns the note reported to the model, the URL of the lost tab is left to restoreLoss
+// when configured so that the navigation does not run under the state lock
+func (ctx *Context) recoverLoss(loss *browserLoss) string {
+	var note string
+	if loss.tabOnly {
+		note = fmt.Sprintf("A new tab was opened because the %s", loss.reason)
+	} else {
+		note = fmt.Sprintf("The browser was relaunched because %s, the previous tabs and refs are gone", loss.reason)
+		if path := ctx.config.StorageStatePath; path != "" {
+			if _, err := os.Stat(path); err == nil {
+				note += fmt.Sprintf(", the storage state was loaded again from %s", path)
+			}
+		}
+	}
+	ctx.restore = nil
+	if ctx.config.RestoreAfterCrash && ctx.activeTab != nil && restorableURL(loss.url) {
+		ctx.restore = &pendingRestore{tab: ctx.activeTab, url: loss.url}
+	}
+	return note
+}
+
+// restoreLoss reopens the URL of the lost tab in the tab replacing it, the navigation is bound to
+// reqCtx so that the client can cancel it, its outcome is added to the recovery note
+func (ctx *Context) restoreLoss(reqCtx context.Context, tab *Tab) {
+	ctx.stateLock.Lock()
+	restore := ctx.restore
+	if restore == nil || restore.tab != tab {
+		ctx.stateLock.Unlock()
+		return
+	}
+	ctx.restore = nil
+	ctx.stateLock.Unlock()
+
+	page := tab.Page.Context(reqCtx).Timeout(ctx.config.navigationTimeout())
+	err := page.Navigate(restore.url)
+	if err == nil {
+		err = page.WaitLoad()
+	}
+	page.CancelTimeout()
+
+	var outcome string
+	if err != nil {
+		outcome = fmt.Sprintf("restoring %s failed: %s", restore.url, err.Error())
+	} else {
+		outcome = fmt.Sprintf("restored %s", restore.url)
+	}
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	if ctx.recovery == "" {
+		ctx.recovery = "After the last relaunch " + outcome
+	} else {
+		ctx.recovery += ", " + outcome
+	}
+}
+
+func restorableURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "file://")
+}
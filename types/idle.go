@@ -0,0 +1,81 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"time"
+)
+
+const (
+	minLifecycleCheckInterval = time.Second
+	maxLifecycleCheckInterval = time.Minute
+)
+
+// Busy marks a tool call in progress, the browser is not closed for idleness or recycled while calls
+// are running, done must be called once the call is finished
+func (ctx *Context) Busy() (done func()) {
+	ctx.busy.Add(1)
+	return func() {
+		ctx.stateLock.Lock()
+		defer ctx.stateLock.Unlock()
+		ctx.lastUsed = time.Now()
+		ctx.busy.Add(-1)
+	}
+}
+
+// watchLifecycle closes the browser once it was idle for browserIdleTimeout or alive for
+// browserMaxLifetime, the next call relaunches it through initial
+func (ctx *Context) watchLifecycle(watchCtx context.Context) {
+	idleTimeout, maxLifetime := ctx.config.BrowserIdleTimeout, ctx.config.BrowserMaxLifetime
+	if idleTimeout <= 0 && maxLifetime <= 0 {
+		return
+	}
+	interval := maxLifecycleCheckInterval
+	for _, d := range []time.Duration{idleTimeout, maxLifetime} {
+		if d > 0 && d/4 < interval {
+			interval = d / 4
+		}
+	}
+	if interval < minLifecycleCheckInterval {
+		interval = minLifecycleCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-watchCtx.Done():
+			return
+		case <-ticker.C:
+			ctx.checkLifecycle(idleTimeout, maxLifetime)
+		}
+	}
+}
+
+func (ctx *Context) checkLifecycle(idleTimeout, maxLifetime time.Duration) {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	if ctx.browser == nil || ctx.busy.Load() > 0 {
+		return
+	}
+	var reason string
+	switch {
+	case idleTimeout > 0 && time.Since(ctx.lastUsed) >= idleTimeout:
+		reason = fmt.Sprintf("it was idle for %s", idleTimeout)
+	case maxLifetime > 0 && time.Since(ctx.launchedAt) >= maxLifetime:
+		reason = fmt.Sprintf("it reached the maximum lifetime of %s", maxLifetime)
+	default:
+		return
+	}
+	log.Infof("Closing the browser because %s", reason)
+	loss := &browserLoss{reason: reason}
+	if ctx.activeTab != nil {
+		loss.url = ctx.activeTab.URL()
+	}
+	closeCtx, cancel := context.WithTimeout(ctx.stdContext, closeBrowserLimit)
+	defer cancel()
+	if err := ctx.closeBrowser(closeCtx); err != nil {
+		log.Warnf("Close browser error: %s", err)
+	}
+	ctx.loss = loss
+}
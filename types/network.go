@@ -0,0 +1,249 @@
+package types
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// NetworkRequest is a request made by a page together with its response
+type NetworkRequest struct {
+	PageID          string            `json:"pageId"`
+	RequestID       string            `json:"requestId"`
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	ResourceType    string            `json:"resourceType,omitempty"`
+	Status          int               `json:"status,omitempty"`
+	StatusText      string            `json:"statusText,omitempty"`
+	MIMEType        string            `json:"mimeType,omitempty"`
+	StartTime       time.Time         `json:"startTime"`
+	DurationMs      float64           `json:"durationMs,omitempty"`
+	EncodedSize     float64           `json:"encodedSize,omitempty"`
+	Finished        bool              `json:"finished"`
+	Failed          bool              `json:"failed,omitempty"`
+	FailureReason   string            `json:"failureReason,omitempty"`
+	RequestHeaders  map[string]string `json:"requestHeaders,omitempty"`
+	ResponseHeaders map[string]string `json:"responseHeaders,omitempty"`
+	RequestBody     string            `json:"requestBody,omitempty"`
+	ResponseBody    string            `json:"responseBody,omitempty"`
+
+	startMonotonic float64
+}
+
+func (r NetworkRequest) String() string {
+	status := "pending"
+	switch {
+	case r.Failed:
+		status = fmt.Sprintf("failed: %s", r.FailureReason)
+	case r.Status != 0:
+		status = fmt.Sprintf("%d %s", r.Status, r.StatusText)
+	}
+	return fmt.Sprintf("[%s] %s %s %s [%s] %.0fms %.0fB", r.StartTime.Format(time.RFC3339Nano), r.Method, r.URL, strings.TrimSpace(status), r.ResourceType, r.DurationMs, r.EncodedSize)
+}
+
+// NetworkFilter selects network requests, zero fields match everything
+type NetworkFilter struct {
+	URLPattern    *regexp.Regexp
+	Methods       []string
+	ResourceTypes []string
+	MinStatus     int
+	MaxStatus     int
+	FailedOnly    bool
+	Since         time.Time
+	PageID        string
+}
+
+func (f NetworkFilter) match(r NetworkRequest) bool {
+	if f.PageID != "" && r.PageID != f.PageID {
+		return false
+	}
+	if !f.Since.IsZero() && r.StartTime.Before(f.Since) {
+		return false
+	}
+	if f.URLPattern != nil && !f.URLPattern.MatchString(r.URL) {
+		return false
+	}
+	if f.FailedOnly && !r.Failed {
+		return false
+	}
+	if f.MinStatus > 0 && r.Status < f.MinStatus {
+		return false
+	}
+	if f.MaxStatus > 0 && (r.Status == 0 || r.Status > f.MaxStatus) {
+		return false
+	}
+	return containsFold(f.Methods, r.Method) && containsFold(f.ResourceTypes, r.ResourceType)
+}
+
+// containsFold reports whether the value is in the list, an empty list contains everything
+func containsFold(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, v := range list {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// NetworkCaptureOptions controls which optional parts of the traffic are recorded
+type NetworkCaptureOptions struct {
+	Headers     bool
+	Bodies      bool
+	MaxBodySize int
+}
+
+// NetworkLogs is a bounded buffer of the network requests of all pages in a context
+type NetworkLogs struct {
+	buffer  *ringBuffer[NetworkRequest]
+	options NetworkCaptureOptions
+}
+
+func NewNetworkLogs(size int, options NetworkCaptureOptions) *NetworkLogs {
+	return &NetworkLogs{
+		buffer:  newRingBuffer[NetworkRequest](size),
+		options: options,
+	}
+}
+
+func (n *NetworkLogs) List(filter NetworkFilter) []NetworkRequest {
+	return n.buffer.list(filter.match)
+}
+
+// Drain lists the requests matching the filter and clears all the requests atomically
+func (n *NetworkLogs) Drain(filter NetworkFilter) []NetworkRequest {
+	return n.buffer.drain(filter.match)
+}
+
+func (n *NetworkLogs) Clear() {
+	n.buffer.clear()
+}
+
+func (n *NetworkLogs) update(pageID string, requestID proto.NetworkRequestID, fn func(NetworkRequest) NetworkRequest) {
+	n.buffer.update(func(r NetworkRequest) bool {
+		return r.PageID == pageID && r.RequestID == string(requestID)
+	}, fn)
+}
+
+func (n *NetworkLogs) truncateBody(body string) string {
+	if n.options.MaxBodySize > 0 && len(body) > n.options.MaxBodySize {
+		cut := n.options.MaxBodySize
+		for cut > 0 && !utf8.RuneStart(body[cut]) {
+			cut--
+		}
+		return fmt.Sprintf("%s...(truncated %d bytes)", body[:cut], len(body)-cut)
+	}
+	return body
+}
+
+// watchNetwork records the network traffic of the page until the page context is done
+func watchNetwork(page *rod.Page, logs *NetworkLogs) {
+	pageID := string(page.TargetID)
+	wait := page.EachEvent(func(e *proto.NetworkRequestWillBeSent) {
+		if e.RedirectResponse != nil {
+			// a redirect reuses the request id, finish the previous hop before recording the new one
+			logs.update(pageID, e.RequestID, func(r NetworkRequest) NetworkRequest {
+				r = applyResponse(r, e.RedirectResponse, logs.options.Headers)
+				r.Finished = true
+				r.DurationMs = (float64(e.Timestamp) - r.startMonotonic) * 1000
+				return r
+			})
+		}
+		r := NetworkRequest{
+			PageID:         pageID,
+			RequestID:      string(e.RequestID),
+			Method:         e.Request.Method,
+			URL:            e.Request.URL + e.Request.URLFragment,
+			ResourceType:   string(e.Type),
+			StartTime:      e.WallTime.Time(),
+			startMonotonic: float64(e.Timestamp),
+		}
+		if logs.options.Headers {
+			r.RequestHeaders = headersMap(e.Request.Headers)
+		}
+		if logs.options.Bodies && e.Request.PostData != "" {
+			r.RequestBody = logs.truncateBody(e.Request.PostData)
+		}
+		logs.buffer.push(r)
+	}, func(e *proto.NetworkResponseReceived) {
+		logs.update(pageID, e.RequestID, func(r NetworkRequest) NetworkRequest {
+			r = applyResponse(r, e.Response, logs.options.Headers)
+			if r.ResourceType == "" {
+				r.ResourceType = string(e.Type)
+			}
+			return r
+		})
+	}, func(e *proto.NetworkLoadingFinished) {
+		logs.update(pageID, e.RequestID, func(r NetworkRequest) NetworkRequest {
+			r.Finished = true
+			r.EncodedSize = e.EncodedDataLength
+			r.DurationMs = (float64(e.Timestamp) - r.startMonotonic) * 1000
+			return r
+		})
+		if logs.options.Bodies {
+			// fetching the body is a CDP call, it must not block the event loop
+			go func(requestID proto.NetworkRequestID) {
+				res, err := proto.NetworkGetResponseBody{RequestID: requestID}.Call(page)
+				if err != nil {
+					return
+				}
+				body := res.Body
+				if res.Base64Encoded {
+					bin, err := base64.StdEncoding.DecodeString(body)
+					if err != nil || !utf8.Valid(bin) {
+						body = fmt.Sprintf("(binary body, %d bytes)", base64.StdEncoding.DecodedLen(len(res.Body)))
+					} else {
+						body = string(bin)
+					}
+				}
+				logs.update(pageID, requestID, func(r NetworkRequest) NetworkRequest {
+					r.ResponseBody = logs.truncateBody(body)
+					return r
+				})
+			}(e.RequestID)
+		}
+	}, func(e *proto.NetworkLoadingFailed) {
+		logs.update(pageID, e.RequestID, func(r NetworkRequest) NetworkRequest {
+			r.Finished = true
+			r.Failed = true
+			r.FailureReason = e.ErrorText
+			if e.BlockedReason != "" {
+				r.FailureReason = fmt.Sprintf("%s (blocked: %s)", e.ErrorText, e.BlockedReason)
+			}
+			if e.Canceled {
+				r.FailureReason = fmt.Sprintf("%s (canceled)", e.ErrorText)
+			}
+			r.DurationMs = (float64(e.Timestamp) - r.startMonotonic) * 1000
+			return r
+		})
+	})
+	go wait()
+}
+
+func applyResponse(r NetworkRequest, res *proto.NetworkResponse, withHeaders bool) NetworkRequest {
+	r.Status = res.Status
+	r.StatusText = res.StatusText
+	r.MIMEType = res.MIMEType
+	if withHeaders {
+		r.ResponseHeaders = headersMap(res.Headers)
+		if len(res.RequestHeaders) > 0 {
+			r.RequestHeaders = headersMap(res.RequestHeaders)
+		}
+	}
+	return r
+}
+
+func headersMap(headers proto.NetworkHeaders) map[string]string {
+	result := make(map[string]string, len(headers))
+	for k, v := range headers {
+		result[k] = v.Str()
+	}
+	return result
+}
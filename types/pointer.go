@@ -0,0 +1,200 @@
+package types
+
+import (
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/ysmood/gson"
+	"sync"
+)
+
+// Pointer is the mouse and the keyboard of a tab, the position, the pressed buttons and the held
+// modifier keys are kept between the tool calls. The input events are dispatched on the page passed
+// to each method so that they end with the context and the timeout of the call, the Mouse and the
+// Keyboard of rod always dispatch on the page they were created with
+type Pointer struct {
+	lock    sync.Mutex
+	pos     proto.Point
+	buttons []proto.InputMouseButton
+	keys    map[input.Key]struct{}
+}
+
+func newPointer() *Pointer {
+	return &Pointer{keys: map[input.Key]struct{}{}}
+}
+
+// Position returns the position of the mouse in the viewport
+func (p *Pointer) Position() proto.Point {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.pos
+}
+
+// MoveTo moves the mouse to the point of the viewport
+func (p *Pointer) MoveTo(page *rod.Page, to proto.Point) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.moveTo(page, to)
+}
+
+// MoveLinear moves the mouse to the point of the viewport in steps moves
+func (p *Pointer) MoveLinear(page *rod.Page, to proto.Point, steps int) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if steps < 1 {
+		steps = 1
+	}
+	from := p.pos
+	step := to.Minus(from).Scale(1 / float64(steps))
+	for i := 1; i < steps; i++ {
+		if err := p.moveTo(page, from.Add(step.Scale(float64(i)))); err != nil {
+			return err
+		}
+	}
+	return p.moveTo(page, to)
+}
+
+func (p *Pointer) moveTo(page *rod.Page, to proto.Point) error {
+	button, buttons := input.EncodeMouseButton(p.buttons)
+	err := proto.InputDispatchMouseEvent{
+		Type:      proto.InputDispatchMouseEventTypeMouseMoved,
+		X:         to.X,
+		Y:         to.Y,
+		Button:    button,
+		Buttons:   gson.Int(buttons),
+		Modifiers: p.modifiers(),
+	}.Call(page)
+	if err != nil {
+		return err
+	}
+	p.pos = to
+	return nil
+}
+
+// Down presses the mouse button at the current position
+func (p *Pointer) Down(page *rod.Page, button proto.InputMouseButton, clickCount int) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.down(page, button, clickCount)
+}
+
+func (p *Pointer) down(page *rod.Page, button proto.InputMouseButton, clickCount int) error {
+	pressed := append(append([]proto.InputMouseButton{}, p.buttons...), button)
+	_, buttons := input.EncodeMouseButton(pressed)
+	err := proto.InputDispatchMouseEvent{
+		Type:       proto.InputDispatchMouseEventTypeMousePressed,
+		X:          p.pos.X,
+		Y:          p.pos.Y,
+		Button:     button,
+		Buttons:    gson.Int(buttons),
+		ClickCount: clickCount,
+		Modifiers:  p.modifiers(),
+	}.Call(page)
+	if err != nil {
+		return err
+	}
+	p.buttons = pressed
+	return nil
+}
+
+// Up releases the mouse button at the current position
+func (p *Pointer) Up(page *rod.Page, button proto.InputMouseButton, clickCount int) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.up(page, button, clickCount)
+}
+
+func (p *Pointer) up(page *rod.Page, button proto.InputMouseButton, clickCount int) error {
+	pressed := make([]proto.InputMouseButton, 0, len(p.buttons))
+	for _, b := range p.buttons {
+		if b != button {
+			pressed = append(pressed, b)
+		}
+	}
+	_, buttons := input.EncodeMouseButton(pressed)
+	err := proto.InputDispatchMouseEvent{
+		Type:       proto.InputDispatchMouseEventTypeMouseReleased,
+		X:          p.pos.X,
+		Y:          p.pos.Y,
+		Button:     button,
+		Buttons:    gson.Int(buttons),
+		ClickCount: clickCount,
+		Modifiers:  p.modifiers(),
+	}.Call(page)
+	if err != nil {
+		return err
+	}
+	p.buttons = pressed
+	return nil
+}
+
+// Click presses and releases the mouse button at the current position
+func (p *Pointer) Click(page *rod.Page, button proto.InputMouseButton, clickCount int) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if err := p.down(page, button, clickCount); err != nil {
+		return err
+	}
+	return p.up(page, button, clickCount)
+}
+
+// Scroll dispatches the wheel distance at the current position in steps events
+func (p *Pointer) Scroll(page *rod.Page, deltaX, deltaY float64, steps int) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if steps < 1 {
+		steps = 1
+	}
+	button, buttons := input.EncodeMouseButton(p.buttons)
+	for i := 0; i < steps; i++ {
+		err := proto.InputDispatchMouseEvent{
+			Type:      proto.InputDispatchMouseEventTypeMouseWheel,
+			X:         p.pos.X,
+			Y:         p.pos.Y,
+			Button:    button,
+			Buttons:   gson.Int(buttons),
+			DeltaX:    deltaX / float64(steps),
+			DeltaY:    deltaY / float64(steps),
+			Modifiers: p.modifiers(),
+		}.Call(page)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Press holds the key down, the held modifier keys apply to the following mouse events
+func (p *Pointer) Press(page *rod.Page, key input.Key) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.keys[key] = struct{}{}
+	return key.Encode(proto.InputDispatchKeyEventTypeKeyDown, p.modifiers()).Call(page)
+}
+
+// Release releases the key, a key which is not held is ignored
+func (p *Pointer) Release(page *rod.Page, key input.Key) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if _, ok := p.keys[key]; !ok {
+		return nil
+	}
+	delete(p.keys, key)
+	return key.Encode(proto.InputDispatchKeyEventTypeKeyUp, p.modifiers()).Call(page)
+}
+
+// Type presses and releases the key
+func (p *Pointer) Type(page *rod.Page, key input.Key) error {
+	if err := p.Press(page, key); err != nil {
+		return err
+	}
+	return p.Release(page, key)
+}
+
+func (p *Pointer) modifiers() int {
+	modifiers := 0
+	for key := range p.keys {
+		modifiers |= key.Modifier()
+	}
+	return modifiers
+}
@@ -0,0 +1,182 @@
+package types
+
+import (
+	"context"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod"
+	"github.com/pkg/errors"
+	"sync"
+	"time"
+)
+
+// BrowserPool shares one launched browser between contexts, each context gets its own
+// incognito browser context with separate cookies, storage and tabs
+type BrowserPool struct {
+	stdContext context.Context
+	config     Config
+	current    *sharedBrowser
+	// retired browsers reached browserMaxLifetime, they are closed once their last context is gone
+	retired []*sharedBrowser
+	lock    sync.Mutex
+}
+
+// sharedBrowser is a launch of the shared browser, contexts counts the incognito contexts of the
+// pool contexts using it
+type sharedBrowser struct {
+	browser    *rod.Browser
+	disconnect func() error
+	cleanup    func(closed bool)
+	launchedAt time.Time
+	contexts   int
+}
+
+func NewBrowserPool(ctx context.Context, cfg Config) *BrowserPool {
+	return &BrowserPool{
+		stdContext: ctx,
+		config:     cfg,
+	}
+}
+
+// NewContext creates a context isolated from the other contexts of the pool,
+// the browser is launched lazily on the first page the context needs
+func (p *BrowserPool) NewContext() *Context {
+	ctx := NewContext(p.stdContext, p.config)
+	ctx.pool = p
+	return ctx
+}
+
+// incognito creates an incognito context of the shared browser, the shared browser is launched when
+// needed and relaunched once it reached browserMaxLifetime. leave must be called with the returned
+// shared browser once the incognito context is closed
+func (p *BrowserPool) incognito() (*rod.Browser, *sharedBrowser, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if shared := p.current; shared != nil && p.expired(shared) {
+		p.current = nil
+		if shared.contexts == 0 {
+			p.closeShared(shared, "it reached the maximum lifetime")
+		} else {
+			// the contexts still using it recycle themselves when they are not busy
+			p.retired = append(p.retired, shared)
+		}
+	}
+	if shared := p.current; shared != nil {
+		if browser, err := shared.browser.Incognito(); err == nil {
+			shared.contexts++
+			return browser, shared, nil
+		}
+		// the shared browser crashed or its connection is gone, launch it again
+		shared.release()
+		p.current = nil
+	}
+	shared := &sharedBrowser{launchedAt: time.Now()}
+	var err error
+	if p.config.RemoteDebuggingURL != "" {
+		shared.browser, shared.disconnect, err = connectBrowser(p.stdContext, p.config)
+	} else {
+		var profile BrowserProfile
+		if profile, err = p.config.pooledProfile(); err == nil {
+			shared.browser, shared.cleanup, err = launchBrowser(p.stdContext, p.config, profile)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	p.current = shared
+	browser, err := shared.browser.Incognito()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "create incognito browser context failed")
+	}
+	shared.contexts++
+	return browser, shared, nil
+}
+
+// leave gives back the shared browser of an incognito context that is gone, the shared browser is
+// closed once its last context left if it is retired or browserIdleTimeout is set
+func (p *BrowserPool) leave(shared *sharedBrowser) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	shared.contexts--
+	if shared.contexts > 0 {
+		return
+	}
+	if shared == p.current {
+		if p.config.BrowserIdleTimeout <= 0 && !p.expired(shared) {
+			return
+		}
+		p.current = nil
+		p.closeShared(shared, "no client uses it")
+		return
+	}
+	for i, retired := range p.retired {
+		if retired == shared {
+			p.retired = append(p.retired[:i], p.retired[i+1:]...)
+			p.closeShared(shared, "it reached the maximum lifetime")
+			return
+		}
+	}
+}
+
+// expired reports whether the shared browser reached browserMaxLifetime
+func (p *BrowserPool) expired(shared *sharedBrowser) bool {
+	return p.config.BrowserMaxLifetime > 0 && time.Since(shared.launchedAt) >= p.config.BrowserMaxLifetime
+}
+
+func (p *BrowserPool) closeShared(shared *sharedBrowser, reason string) {
+	log.Infof("Closing the shared browser because %s", reason)
+	if err := shared.close(); err != nil {
+		log.Warnf("Close shared browser error: %s", err)
+	}
+}
+
+// release forgets the shared browser that is no longer reachable
+func (b *sharedBrowser) release() {
+	if b.disconnect != nil {
+		_ = b.disconnect()
+		b.disconnect = nil
+	}
+	if b.cleanup != nil {
+		b.cleanup(false)
+		b.cleanup = nil
+	}
+}
+
+func (b *sharedBrowser) close() error {
+	if b.disconnect != nil {
+		err := b.disconnect()
+		b.disconnect = nil
+		if err != nil {
+			return errors.Wrap(err, "disconnect remote browser failed")
+		}
+		return nil
+	}
+	err := b.browser.Close()
+	if b.cleanup != nil {
+		b.cleanup(err == nil)
+		b.cleanup = nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "close browser failed")
+	}
+	return nil
+}
+
+// Close the shared browser, the contexts of the pool should be closed first
+func (p *BrowserPool) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var err error
+	for _, retired := range p.retired {
+		if e := retired.close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	p.retired = nil
+	if p.current != nil {
+		if e := p.current.close(); e != nil && err == nil {
+			err = e
+		}
+		p.current = nil
+	}
+	return err
+}
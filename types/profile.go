@@ -0,0 +1,203 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"github.com/charmbracelet/log"
+	"github.com/go-rod/rod-mcp/utils"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	profilesDirName = "profiles"
+	tempDirLength   = 10
+	// tempDirMarker is written into the random user data dirs so that only the dirs created by
+	// rod-mcp are ever cleaned up
+	tempDirMarker = ".rod-mcp"
+)
+
+// tempDirRegexp matches the random user data dirs of ephemeral launches
+var tempDirRegexp = regexp.MustCompile(fmt.Sprintf(`^[a-zA-Z]{%d}$`, tempDirLength))
+
+// BrowserProfile is a named browser setup, the user data dir of a persistent profile keeps
+// logins and cookies between launches while an ephemeral profile starts clean every launch
+type BrowserProfile struct {
+	Name string `yaml:"name" json:"name"`
+	// UserDataDir defaults to profiles/<name> in the browser temp dir
+	UserDataDir string `yaml:"userDataDir" json:"userDataDir"`
+	// Proxy and Headless fall back to the global config when not set
+	Proxy    string `yaml:"proxy" json:"proxy"`
+	Headless *bool  `yaml:"headless" json:"headless"`
+	// Flags are extra command line flags of the browser, an empty value sets a flag without value
+	Flags map[string]string `yaml:"flags" json:"flags"`
+	// Ephemeral launches use a random user data dir which is deleted when the browser is closed
+	Ephemeral bool `yaml:"ephemeral" json:"ephemeral"`
+}
+
+// FindProfile returns the profile with the name, an empty name is the default profile of the config,
+// without a default profile the browser runs ephemeral
+func (cfg Config) FindProfile(name string) (BrowserProfile, error) {
+	if name == "" {
+		name = cfg.Profile
+	}
+	if name == "" {
+		return BrowserProfile{Ephemeral: true}, nil
+	}
+	for _, profile := range cfg.Profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+	}
+	return BrowserProfile{}, errors.Errorf("profile %s not found", name)
+}
+
+// pooledProfile returns the profile the shared browser of the client sessions is launched with, the
+// sessions run in incognito contexts which never see the cookies and storage of the user data dir,
+// so only an ephemeral profile is accepted
+func (cfg Config) pooledProfile() (BrowserProfile, error) {
+	profile, err := cfg.FindProfile("")
+	if err != nil {
+		return BrowserProfile{}, err
+	}
+	if !profile.Ephemeral {
+		return BrowserProfile{}, errors.Errorf("profile %s keeps its data in %s which the incognito contexts of the %s transport can not use, "+
+			"use the %s transport or an ephemeral profile", profile.Name, profile.userDataDir(cfg), cfg.Transport, TransportStdio)
+	}
+	return profile, nil
+}
+
+func (cfg Config) browserTempDir() string {
+	if cfg.BrowserTempDir == "" {
+		return DefaultBrowserTempDir
+	}
+	return cfg.BrowserTempDir
+}
+
+// userDataDir returns the user data dir of a launch of the profile
+func (p BrowserProfile) userDataDir(cfg Config) string {
+	switch {
+	case p.Ephemeral:
+		// browser must own a unique temp dir
+		return filepath.Join(cfg.browserTempDir(), utils.RandomString(tempDirLength))
+	case p.UserDataDir != "":
+		return p.UserDataDir
+	default:
+		return filepath.Join(cfg.browserTempDir(), profilesDirName, p.Name)
+	}
+}
+
+func (p BrowserProfile) headless(cfg Config) bool {
+	if p.Headless != nil {
+		return *p.Headless
+	}
+	return cfg.Headless
+}
+
+func (p BrowserProfile) proxy(cfg Config) string {
+	if p.Proxy != "" {
+		return p.Proxy
+	}
+	return cfg.Proxy
+}
+
+// String describes the profile in one line
+func (p BrowserProfile) String() string {
+	parts := []string{p.Name}
+	if p.Ephemeral {
+		parts = append(parts, "ephemeral")
+	} else if p.UserDataDir != "" {
+		parts = append(parts, "dir: "+p.UserDataDir)
+	}
+	if p.Headless != nil {
+		parts = append(parts, fmt.Sprintf("headless: %t", *p.Headless))
+	}
+	if p.Proxy != "" {
+		parts = append(parts, "proxy: "+p.Proxy)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// markTempDir creates the random user data dir of an ephemeral launch with its marker
+func markTempDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return errors.Wrapf(err, "create browser temp dir %s failed", dir)
+	}
+	if err := os.WriteFile(filepath.Join(dir, tempDirMarker), nil, 0o644); err != nil {
+		return errors.Wrapf(err, "mark browser temp dir %s failed", dir)
+	}
+	return nil
+}
+
+// CleanTempDirs removes the random user data dirs left behind by ephemeral launches that did not
+// shut down cleanly, only the dirs carrying the marker of rod-mcp are removed and the dirs still
+// locked by a running browser are kept
+func CleanTempDirs(cfg Config) ([]string, error) {
+	dir := cfg.browserTempDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "read browser temp dir %s failed", dir)
+	}
+	var removed []string
+	for _, entry := range entries {
+		if !entry.IsDir() || !tempDirRegexp.MatchString(entry.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(filepath.Join(path, tempDirMarker)); err != nil {
+			continue
+		}
+		if browserRunning(path) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			log.Warnf("Remove orphaned browser temp dir %s error: %s", path, err)
+			continue
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// waitBrowserExit waits until the browser using the user data dir is gone or the wait is over
+func waitBrowserExit(userDataDir string, wait time.Duration) {
+	deadline := time.Now().Add(wait)
+	for browserRunning(userDataDir) && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// Profile returns the name of the profile the browser of the context is launched with
+func (ctx *Context) Profile() string {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	return ctx.profile
+}
+
+// SwitchProfile closes the browser with the calls bound to reqCtx, the next tool call launches it again
+// with the profile
+func (ctx *Context) SwitchProfile(reqCtx context.Context, name string) error {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	if ctx.config.RemoteDebuggingURL != "" {
+		return errors.New("profiles do not apply to a remote browser")
+	}
+	if ctx.pool != nil {
+		return errors.New("profiles can not be switched while client sessions share the browser")
+	}
+	if _, err := ctx.config.FindProfile(name); err != nil {
+		return err
+	}
+	if err := ctx.closeBrowser(reqCtx); err != nil {
+		return err
+	}
+	ctx.profile = name
+	return nil
+}
@@ -0,0 +1,34 @@
+//go:build !windows
+
+package types
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const singletonLock = "SingletonLock"
+
+// browserRunning checks the lock chrome keeps in its user data dir, the lock links to `<host>-<pid>`
+func browserRunning(userDataDir string) bool {
+	target, err := os.Readlink(filepath.Join(userDataDir, singletonLock))
+	if err != nil {
+		return false
+	}
+	i := strings.LastIndex(target, "-")
+	if i < 0 {
+		return true
+	}
+	pid, err := strconv.Atoi(target[i+1:])
+	if err != nil {
+		return true
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package types
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const lockFile = "lockfile"
+
+// browserRunning checks the lock file chrome keeps open without sharing in its user data dir, the
+// file can only be opened once the browser is gone
+func browserRunning(userDataDir string) bool {
+	file, err := os.OpenFile(filepath.Join(userDataDir, lockFile), os.O_RDWR, 0)
+	if err != nil {
+		return !os.IsNotExist(err)
+	}
+	_ = file.Close()
+	return false
+}
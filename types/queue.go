@@ -0,0 +1,105 @@
+package types
+
+import (
+	"context"
+	"sync"
+)
+
+// actionQueue orders the actions on a tab, the actions are granted in the order they arrive, the
+// read-only actions run together while a mutating action runs alone
+type actionQueue struct {
+	lock    sync.Mutex
+	readers int
+	writing bool
+	waiters []*actionWaiter
+}
+
+type actionWaiter struct {
+	readOnly bool
+	ready    chan struct{}
+}
+
+// acquire waits for the turn of the action until ctx is done, release must be called once the
+// action is finished
+func (q *actionQueue) acquire(ctx context.Context, readOnly bool) (release func(), err error) {
+	q.lock.Lock()
+	if len(q.waiters) == 0 && q.grantable(readOnly) {
+		q.grant(readOnly)
+		q.lock.Unlock()
+		return q.releaser(readOnly), nil
+	}
+	w := &actionWaiter{readOnly: readOnly, ready: make(chan struct{})}
+	q.waiters = append(q.waiters, w)
+	q.lock.Unlock()
+
+	select {
+	case <-w.ready:
+		return q.releaser(readOnly), nil
+	case <-ctx.Done():
+		q.lock.Lock()
+		defer q.lock.Unlock()
+		select {
+		case <-w.ready:
+			// granted while giving up, hand the turn over
+			q.release(readOnly)
+		default:
+			q.remove(w)
+			q.dispatch()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (q *actionQueue) grantable(readOnly bool) bool {
+	if readOnly {
+		return !q.writing
+	}
+	return !q.writing && q.readers == 0
+}
+
+func (q *actionQueue) grant(readOnly bool) {
+	if readOnly {
+		q.readers++
+	} else {
+		q.writing = true
+	}
+}
+
+func (q *actionQueue) releaser(readOnly bool) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.lock.Lock()
+			defer q.lock.Unlock()
+			q.release(readOnly)
+		})
+	}
+}
+
+func (q *actionQueue) release(readOnly bool) {
+	if readOnly {
+		q.readers--
+	} else {
+		q.writing = false
+	}
+	q.dispatch()
+}
+
+// dispatch grants the waiters at the head of the queue as long as they can run
+func (q *actionQueue) dispatch() {
+	for len(q.waiters) > 0 && q.grantable(q.waiters[0].readOnly) {
+		w := q.waiters[0]
+		q.waiters = q.waiters[1:]
+		q.grant(w.readOnly)
+		close(w.ready)
+	}
+}
+
+func (q *actionQueue) remove(w *actionWaiter) {
+	for i, waiter := range q.waiters {
+		if waiter == w {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			return
+		}
+	}
+}
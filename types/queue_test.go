@@ -0,0 +1,217 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitGranted reports whether the acquire running in the background is granted within a short wait
+func waitGranted(done <-chan func()) (func(), bool) {
+	select {
+	case release := <-done:
+		return release, true
+	case <-time.After(50 * time.Millisecond):
+		return nil, false
+	}
+}
+
+func acquireAsync(t *testing.T, q *actionQueue, ctx context.Context, readOnly bool) <-chan func() {
+	t.Helper()
+	done := make(chan func(), 1)
+	go func() {
+		release, err := q.acquire(ctx, readOnly)
+		if err != nil {
+			return
+		}
+		done <- release
+	}()
+	// let the waiter join the queue before the next one
+	time.Sleep(10 * time.Millisecond)
+	return done
+}
+
+func TestActionQueueGrants(t *testing.T) {
+	tests := []struct {
+		name    string
+		holding []bool
+		next    bool
+		granted bool
+	}{
+		{name: "reader after readers", holding: []bool{true, true}, next: true, granted: true},
+		{name: "writer after reader", holding: []bool{true}, next: false, granted: false},
+		{name: "reader after writer", holding: []bool{false}, next: true, granted: false},
+		{name: "writer after writer", holding: []bool{false}, next: false, granted: false},
+		{name: "writer on idle queue", next: false, granted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &actionQueue{}
+			for _, readOnly := range tt.holding {
+				if _, err := q.acquire(context.Background(), readOnly); err != nil {
+					t.Fatalf("acquire() error = %v", err)
+				}
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			_, granted := waitGranted(acquireAsync(t, q, ctx, tt.next))
+			if granted != tt.granted {
+				t.Errorf("granted = %v, want %v", granted, tt.granted)
+			}
+		})
+	}
+}
+
+func TestActionQueueOrder(t *testing.T) {
+	q := &actionQueue{}
+	release, err := q.acquire(context.Background(), false)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	ctx := context.Background()
+	reader1 := acquireAsync(t, q, ctx, true)
+	writer := acquireAsync(t, q, ctx, false)
+	// a reader arriving after a waiting writer must not overtake it
+	reader2 := acquireAsync(t, q, ctx, true)
+
+	release()
+	releaseReader1, ok := waitGranted(reader1)
+	if !ok {
+		t.Fatal("the first reader was not granted after the writer released")
+	}
+	if _, ok := waitGranted(writer); ok {
+		t.Fatal("the writer was granted while a reader was running")
+	}
+	if _, ok := waitGranted(reader2); ok {
+		t.Fatal("the second reader overtook the waiting writer")
+	}
+
+	releaseReader1()
+	releaseWriter, ok := waitGranted(writer)
+	if !ok {
+		t.Fatal("the writer was not granted after the reader released")
+	}
+	if _, ok := waitGranted(reader2); ok {
+		t.Fatal("the second reader was granted while the writer was running")
+	}
+
+	releaseWriter()
+	releaseWriter()
+	if _, ok := waitGranted(reader2); !ok {
+		t.Fatal("the second reader was not granted after the writer released")
+	}
+	if q.writing || q.readers != 1 {
+		t.Errorf("writing = %v, readers = %d after a double release, want false, 1", q.writing, q.readers)
+	}
+}
+
+func TestActionQueueCancel(t *testing.T) {
+	q := &actionQueue{}
+	release, err := q.acquire(context.Background(), false)
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		_, err := q.acquire(ctx, false)
+		errs <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	reader := acquireAsync(t, q, context.Background(), true)
+
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("acquire() error = %v, want %v", err, context.Canceled)
+	}
+	if _, ok := waitGranted(reader); ok {
+		t.Fatal("the reader was granted while the writer was running")
+	}
+	release()
+	if _, ok := waitGranted(reader); !ok {
+		t.Fatal("the reader behind the canceled writer was not granted")
+	}
+}
+
+func TestActionQueueCancelWhileGranted(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		q := &actionQueue{}
+		release, err := q.acquire(context.Background(), false)
+		if err != nil {
+			t.Fatalf("acquire() error = %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		result := make(chan func(), 1)
+		go func() {
+			release, err := q.acquire(ctx, false)
+			if err != nil {
+				release = nil
+			}
+			result <- release
+		}()
+		time.Sleep(time.Millisecond)
+		// the turn is handed over and the waiter gives up at the same time
+		go cancel()
+		release()
+		if release := <-result; release != nil {
+			release()
+		}
+		// whatever side won, the queue must be free again
+		if _, err := q.acquire(context.Background(), false); err != nil {
+			t.Fatalf("acquire() error = %v", err)
+		}
+		if !q.writing || q.readers != 0 || len(q.waiters) != 0 {
+			t.Fatalf("writing = %v, readers = %d, waiters = %d", q.writing, q.readers, len(q.waiters))
+		}
+	}
+}
+
+func TestActionQueueConcurrent(t *testing.T) {
+	q := &actionQueue{}
+	var readers, writers atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		readOnly := i%3 != 0
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				// some actions give up while waiting for their turn
+				ctx, cancel := context.WithTimeout(context.Background(), time.Duration(j%4)*time.Millisecond)
+				if j%4 == 0 {
+					cancel()
+					ctx, cancel = context.WithCancel(context.Background())
+				}
+				release, err := q.acquire(ctx, readOnly)
+				cancel()
+				if err != nil {
+					continue
+				}
+				if readOnly {
+					readers.Add(1)
+					if writers.Load() != 0 {
+						t.Error("a reader runs with a writer")
+					}
+				} else {
+					if writers.Add(1) != 1 || readers.Load() != 0 {
+						t.Error("a writer does not run alone")
+					}
+				}
+				time.Sleep(100 * time.Microsecond)
+				if readOnly {
+					readers.Add(-1)
+				} else {
+					writers.Add(-1)
+				}
+				release()
+			}
+		}()
+	}
+	wg.Wait()
+	if q.writing || q.readers != 0 || len(q.waiters) != 0 {
+		t.Errorf("writing = %v, readers = %d, waiters = %d after all the actions", q.writing, q.readers, len(q.waiters))
+	}
+}
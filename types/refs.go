@@ -0,0 +1,60 @@
+package types
+
+import (
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+// ElementRefs maps the short element references of the latest page snapshot to their DOM nodes,
+// the references are invalidated when a new snapshot is taken or the page navigates
+type ElementRefs struct {
+	lock sync.RWMutex
+	refs map[string]proto.DOMBackendNodeID
+}
+
+func newElementRefs() *ElementRefs {
+	return &ElementRefs{refs: map[string]proto.DOMBackendNodeID{}}
+}
+
+// Replace drops the previous references and keeps the given ones
+func (r *ElementRefs) Replace(refs map[string]proto.DOMBackendNodeID) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.refs = refs
+}
+
+func (r *ElementRefs) Reset() {
+	r.Replace(map[string]proto.DOMBackendNodeID{})
+}
+
+func (r *ElementRefs) Lookup(ref string) (proto.DOMBackendNodeID, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	id, ok := r.refs[ref]
+	return id, ok
+}
+
+// Resolve finds the element of the reference on the page it was taken from
+func (r *ElementRefs) Resolve(page *rod.Page, ref string) (*rod.Element, error) {
+	id, ok := r.Lookup(ref)
+	if !ok {
+		return nil, errors.Errorf("ref %s not found, take a new snapshot with rod_snapshot", ref)
+	}
+	el, err := page.ElementFromNode(&proto.DOMNode{BackendNodeID: id})
+	if err != nil {
+		return nil, errors.Wrapf(err, "ref %s is stale, take a new snapshot with rod_snapshot", ref)
+	}
+	return el, nil
+}
+
+// watchNavigation invalidates the references of the tab when its main frame navigates
+func watchNavigation(page *rod.Page, refs *ElementRefs) {
+	wait := page.EachEvent(func(e *proto.PageFrameNavigated) {
+		if e.Frame.ParentID == "" {
+			refs.Reset()
+		}
+	})
+	go wait()
+}
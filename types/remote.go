@@ -0,0 +1,46 @@
+package types
+
+import (
+	"context"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/cdp"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/pkg/errors"
+)
+
+// connectBrowser attaches to the running browser of cfg.RemoteDebuggingURL instead of launching one,
+// disconnect only closes the connection, the browser keeps running
+func connectBrowser(ctx context.Context, cfg Config) (browser *rod.Browser, disconnect func() error, err error) {
+	controlUrl, err := launcher.ResolveURL(cfg.RemoteDebuggingURL)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "resolve remote debugging url %s failed", cfg.RemoteDebuggingURL)
+	}
+
+	ws := &cdp.WebSocket{}
+	if err := ws.Connect(ctx, controlUrl, nil); err != nil {
+		return nil, nil, errors.Wrapf(err, "connect remote browser %s failed", controlUrl)
+	}
+	browser = rod.New().Context(ctx).Client(cdp.New().Start(ws))
+	if err := browser.Connect(); err != nil {
+		_ = ws.Close()
+		return nil, nil, errors.Wrapf(err, "connect remote browser %s failed", controlUrl)
+	}
+	return browser, ws.Close, nil
+}
+
+// adoptPages tracks the pages already open in the remote browser as tabs, the first page listed
+// by the browser becomes the active tab
+func (ctx *Context) adoptPages() error {
+	pages, err := ctx.browser.Pages()
+	if err != nil {
+		return errors.Wrap(err, "list pages of remote browser failed")
+	}
+	for _, page := range pages {
+		tab := ctx.registerTab(page)
+		tab.external = true
+		if ctx.activeTab == nil {
+			ctx.activeTab = tab
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package types
+
+import "sync"
+
+// ringBuffer keeps the latest items up to its capacity, the oldest items are dropped first
+type ringBuffer[T any] struct {
+	lock  sync.RWMutex
+	items []T
+	start int
+	size  int
+}
+
+func newRingBuffer[T any](capacity int) *ringBuffer[T] {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &ringBuffer[T]{items: make([]T, capacity)}
+}
+
+func (r *ringBuffer[T]) push(item T) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.size < len(r.items) {
+		r.items[(r.start+r.size)%len(r.items)] = item
+		r.size++
+		return
+	}
+	r.items[r.start] = item
+	r.start = (r.start + 1) % len(r.items)
+}
+
+// update replaces the newest item matching the predicate, it reports whether an item was found
+func (r *ringBuffer[T]) update(match func(T) bool, fn func(T) T) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i := r.size - 1; i >= 0; i-- {
+		idx := (r.start + i) % len(r.items)
+		if match(r.items[idx]) {
+			r.items[idx] = fn(r.items[idx])
+			return true
+		}
+	}
+	return false
+}
+
+// list returns the items matching the filter from the oldest to the newest
+func (r *ringBuffer[T]) list(filter func(T) bool) []T {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.collect(filter)
+}
+
+// drain returns the items matching the filter like list and clears all the items at once, so no
+// item pushed in between is dropped without being read
+func (r *ringBuffer[T]) drain(filter func(T) bool) []T {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	result := r.collect(filter)
+	r.reset()
+	return result
+}
+
+func (r *ringBuffer[T]) clear() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.reset()
+}
+
+func (r *ringBuffer[T]) collect(filter func(T) bool) []T {
+	result := make([]T, 0, r.size)
+	for i := 0; i < r.size; i++ {
+		item := r.items[(r.start+i)%len(r.items)]
+		if filter == nil || filter(item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (r *ringBuffer[T]) reset() {
+	var zero T
+	for i := range r.items {
+		r.items[i] = zero
+	}
+	r.start, r.size = 0, 0
+}
@@ -0,0 +1,24 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRingBufferDrain(t *testing.T) {
+	r := newRingBuffer[int](3)
+	for i := 1; i <= 4; i++ {
+		r.push(i)
+	}
+	odd := func(i int) bool { return i%2 == 1 }
+	if got, want := r.drain(odd), []int{3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("drain() = %v, want %v", got, want)
+	}
+	if got := r.list(nil); len(got) != 0 {
+		t.Errorf("list() after drain = %v, want none", got)
+	}
+	r.push(5)
+	if got, want := r.drain(nil), []int{5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("drain() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,303 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod-mcp/utils"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// storageCallLimit bounds a single call reading or writing the storages so that a hung tab or browser
+// does not block the caller
+const storageCallLimit = 5 * time.Second
+
+// collectStorageJS reads the storages of the document, opaque origins such as about:blank have none
+const collectStorageJS = `() => {
+	if (location.origin === 'null') {
+		return null;
+	}
+	const items = (storage) => Object.keys(storage).map(name => ({ name, value: storage.getItem(name) }));
+	return { origin: location.origin, localStorage: items(localStorage), sessionStorage: items(sessionStorage) };
+}`
+
+// restoreStorageJS writes the items into the storages of the document
+const restoreStorageJS = `(local, session) => {
+	(local || []).forEach(item => localStorage.setItem(item.name, item.value));
+	(session || []).forEach(item => sessionStorage.setItem(item.name, item.value));
+}`
+
+// blankDocument answers the document requests while the storages are restored
+const blankDocument = "<!DOCTYPE html><html><head></head><body></body></html>"
+
+// StorageState is the authentication state of a browser, the layout is compatible with
+// the storageState files of Playwright, sessionStorage is an extension of the format
+type StorageState struct {
+	Cookies []StorageCookie `json:"cookies"`
+	Origins []OriginStorage `json:"origins"`
+}
+
+type StorageCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Domain starting with a dot matches the subdomains too
+	Domain string `json:"domain"`
+	Path   string `json:"path"`
+	// Expires is the unix time in seconds, -1 is a session cookie
+	Expires  float64 `json:"expires"`
+	HTTPOnly bool    `json:"httpOnly"`
+	Secure   bool    `json:"secure"`
+	SameSite string  `json:"sameSite"`
+}
+
+type OriginStorage struct {
+	Origin         string        `json:"origin"`
+	LocalStorage   []StorageItem `json:"localStorage"`
+	SessionStorage []StorageItem `json:"sessionStorage,omitempty"`
+}
+
+type StorageItem struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ReadStorageState reads a storage state file
+func ReadStorageState(path string) (*StorageState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read storage state %s failed", path)
+	}
+	var state StorageState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, errors.Wrapf(err, "parse storage state %s failed", path)
+	}
+	return &state, nil
+}
+
+// WriteStorageState writes the storage state to the file, the directories are created if needed
+func WriteStorageState(path string, state *StorageState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "encode storage state failed")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "create directory of %s failed", path)
+	}
+	// the state holds credentials, keep it private
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return errors.Wrapf(err, "write storage state %s failed", path)
+	}
+	return nil
+}
+
+// SaveStorageState collects the cookies of the browser and the storages of the origins open in the tabs,
+// the sessionStorage of an origin comes from the active tab when several tabs show it. The browser is
+// read outside of the state lock and the reads end with reqCtx
+func (ctx *Context) SaveStorageState(reqCtx context.Context) (*StorageState, error) {
+	ctx.stateLock.Lock()
+	if err := ctx.ready(); err != nil {
+		ctx.stateLock.Unlock()
+		return nil, err
+	}
+	browser := ctx.browser
+	tabs := append([]*Tab{ctx.activeTab}, ctx.tabs...)
+	ctx.stateLock.Unlock()
+
+	cookies, err := browser.Context(reqCtx).GetCookies()
+	if err != nil {
+		return nil, errors.Wrap(err, "get cookies failed")
+	}
+	state := &StorageState{
+		Cookies: make([]StorageCookie, 0, len(cookies)),
+		Origins: make([]OriginStorage, 0),
+	}
+	for _, c := range cookies {
+		state.Cookies = append(state.Cookies, storageCookie(c))
+	}
+
+	seen := map[string]bool{}
+	for _, tab := range tabs {
+		if tab == nil {
+			continue
+		}
+		page := tab.Page.Context(reqCtx).Timeout(storageCallLimit)
+		res, err := page.Eval(collectStorageJS)
+		page.CancelTimeout()
+		if err != nil || res.Value.Nil() {
+			continue
+		}
+		var origin OriginStorage
+		if err := res.Value.Unmarshal(&origin); err != nil || seen[origin.Origin] {
+			continue
+		}
+		seen[origin.Origin] = true
+		state.Origins = append(state.Origins, origin)
+	}
+	return state, nil
+}
+
+// LoadStorageState adds the cookies to the browser and restores the storages of the origins, the page
+// visits every origin with the requests answered by a blank page and then returns. The page should be
+// bound to the request, the visits run outside of the state lock and end with the context of the page
+func (ctx *Context) LoadStorageState(page *rod.Page, state *StorageState) error {
+	browser, err := ctx.readyBrowser()
+	if err != nil {
+		return err
+	}
+	if err := setStateCookies(browser.Context(page.GetContext()), state.Cookies); err != nil {
+		return err
+	}
+	return restoreOrigins(page, state.Origins, ctx.config.navigationTimeout())
+}
+
+func setStateCookies(browser *rod.Browser, cookies []StorageCookie) error {
+	if len(cookies) == 0 {
+		return nil
+	}
+	params := make([]*proto.NetworkCookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		params = append(params, cookieParam(c))
+	}
+	if err := browser.SetCookies(params); err != nil {
+		return errors.Wrap(err, "set cookies failed")
+	}
+	return nil
+}
+
+// restoreOrigins visits the origins in the page to restore their storages and returns to the current
+// page, every visit is bounded by timeout
+func restoreOrigins(page *rod.Page, origins []OriginStorage, timeout time.Duration) error {
+	if len(origins) == 0 {
+		return nil
+	}
+	current := "about:blank"
+	if info, err := page.Info(); err == nil && info.URL != "" {
+		current = info.URL
+	}
+
+	router := page.HijackRequests()
+	err := router.Add("*", proto.NetworkResourceTypeDocument, func(h *rod.Hijack) {
+		h.Response.SetHeader("Content-Type", "text/html; charset=utf-8")
+		h.Response.SetBody(blankDocument)
+	})
+	if err != nil {
+		return errors.Wrap(err, "intercept requests failed")
+	}
+	go router.Run()
+
+	restore := func(origin OriginStorage) error {
+		page := page.Timeout(timeout)
+		defer page.CancelTimeout()
+		if err := page.Navigate(origin.Origin); err != nil {
+			return errors.Wrapf(err, "open origin %s failed", origin.Origin)
+		}
+		if err := page.WaitLoad(); err != nil {
+			return errors.Wrapf(err, "open origin %s failed", origin.Origin)
+		}
+		if _, err := page.Eval(restoreStorageJS, origin.LocalStorage, origin.SessionStorage); err != nil {
+			return errors.Wrapf(err, "restore storage of origin %s failed", origin.Origin)
+		}
+		return nil
+	}
+	for _, origin := range origins {
+		if err = restore(origin); err != nil {
+			break
+		}
+	}
+	_ = router.Stop()
+	back := page.Timeout(timeout)
+	if navErr := back.Navigate(current); navErr != nil && err == nil {
+		err = errors.Wrapf(navErr, "return to %s failed", current)
+	}
+	back.CancelTimeout()
+	return err
+}
+
+// autoLoadStorageState applies the storage state file of the config to a freshly launched browser, the
+// cookies are set right away while the storages of the origins are left to restoreAutoLoaded so that
+// the visits do not run under the state lock
+func (ctx *Context) autoLoadStorageState() error {
+	ctx.autoLoaded = nil
+	path := ctx.config.StorageStatePath
+	if exist, _ := utils.PathExists(path); !exist {
+		return nil
+	}
+	state, err := ReadStorageState(path)
+	if err != nil {
+		return err
+	}
+	if err := setStateCookies(ctx.browser.Timeout(storageCallLimit), state.Cookies); err != nil {
+		return err
+	}
+	ctx.autoLoaded = state.Origins
+	return nil
+}
+
+// restoreAutoLoaded restores the storages of the origins of the auto-loaded storage state through the
+// tab before its first action, the visits are bound to reqCtx. A tab rod-mcp did not open is not
+// navigated away, a blank page of its own visits the origins instead
+func (ctx *Context) restoreAutoLoaded(reqCtx context.Context, tab *Tab) error {
+	ctx.stateLock.Lock()
+	origins := ctx.autoLoaded
+	ctx.autoLoaded = nil
+	browser := ctx.browser
+	ctx.stateLock.Unlock()
+	if len(origins) == 0 {
+		return nil
+	}
+
+	if !tab.external {
+		return restoreOrigins(tab.Page.Context(reqCtx), origins, ctx.config.navigationTimeout())
+	}
+	page, err := openPage(reqCtx, browser, "")
+	if err != nil {
+		return err
+	}
+	page = page.Context(reqCtx)
+	err = restoreOrigins(page, origins, ctx.config.navigationTimeout())
+	if closeErr := page.Close(); closeErr != nil && err == nil {
+		err = errors.Wrap(closeErr, "close page failed")
+	}
+	return err
+}
+
+func storageCookie(c *proto.NetworkCookie) StorageCookie {
+	expires := float64(c.Expires)
+	if c.Session {
+		expires = -1
+	}
+	sameSite := string(c.SameSite)
+	if sameSite == "" {
+		sameSite = string(proto.NetworkCookieSameSiteLax)
+	}
+	return StorageCookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		Expires:  expires,
+		HTTPOnly: c.HTTPOnly,
+		Secure:   c.Secure,
+		SameSite: sameSite,
+	}
+}
+
+func cookieParam(c StorageCookie) *proto.NetworkCookieParam {
+	param := &proto.NetworkCookieParam{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HTTPOnly: c.HTTPOnly,
+		SameSite: proto.NetworkCookieSameSite(c.SameSite),
+	}
+	if c.Expires > 0 {
+		param.Expires = proto.TimeSinceEpoch(c.Expires)
+	}
+	return param
+}
@@ -0,0 +1,301 @@
+package types
+
+import (
+	"context"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/pkg/errors"
+	"sync/atomic"
+)
+
+// Tab is a browser page tracked by the context
+type Tab struct {
+	ID         string
+	Page       *rod.Page
+	Refs       *ElementRefs
+	Pointer    *Pointer
+	stopEvents context.CancelFunc
+	// external tabs were not opened by rod-mcp, they stay open when a remote browser is detached
+	external bool
+	actions  actionQueue
+	url      atomic.Value
+}
+
+// Tabs returns the tabs of the context in the order they were opened
+func (ctx *Context) Tabs() []*Tab {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	return append([]*Tab(nil), ctx.tabs...)
+}
+
+// ActiveTab returns the tab the tools operate on, nil if the browser has no tab yet
+func (ctx *Context) ActiveTab() *Tab {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	return ctx.activeTab
+}
+
+// QueueAction waits until the action can run on the active tab, the mutating actions of a tab run one
+// at a time in the order they arrive while the read-only actions run concurrently, the actions of
+// different tabs do not wait for each other but they all wait for the browser actions queued before
+// them. The wait ends with an error when waitCtx is done.
+// The tab the turn was taken on is returned, the action must run on it even if another tab became
+// active meanwhile, release must be called once the action is finished
+func (ctx *Context) QueueAction(waitCtx context.Context, readOnly bool) (tab *Tab, release func(), err error) {
+	releaseBrowser, err := ctx.actions.acquire(waitCtx, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			releaseBrowser()
+		}
+	}()
+	if err := ctx.initial(); err != nil {
+		return nil, nil, err
+	}
+	tab = ctx.ActiveTab()
+	if tab == nil {
+		return nil, nil, errors.New("no active tab")
+	}
+	releaseTab, err := tab.actions.acquire(waitCtx, readOnly)
+	if err != nil {
+		return nil, nil, err
+	}
+	release = func() {
+		releaseTab()
+		releaseBrowser()
+	}
+	// the auto-loaded storages and the URL of a crashed tab are restored before the first action on the tab
+	if err := ctx.restoreAutoLoaded(waitCtx, tab); err != nil {
+		releaseTab()
+		return nil, nil, errors.Wrap(err, "load storage state failed")
+	}
+	ctx.restoreLoss(waitCtx, tab)
+	return tab, release, nil
+}
+
+// QueueBrowserAction waits until the action on the whole browser can run, such as changing the cookies
+// or closing tabs. A mutating browser action waits for the running actions of all the tabs and runs
+// alone, a read-only one only waits for the mutating browser actions. The wait ends with an error when
+// waitCtx is done, release must be called once the action is finished
+func (ctx *Context) QueueBrowserAction(waitCtx context.Context, readOnly bool) (release func(), err error) {
+	return ctx.actions.acquire(waitCtx, readOnly)
+}
+
+// TabPage returns the page of the tab, an error is returned once the tab is closed
+func (ctx *Context) TabPage(tab *Tab) (*rod.Page, error) {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	if ctx.findTab(tab.ID) != tab {
+		return nil, errors.Errorf("tab %s was closed", tab.ID)
+	}
+	return tab.Page, nil
+}
+
+// TabOf returns the tab of the page, nil if the page is not tracked
+func (ctx *Context) TabOf(page *rod.Page) *Tab {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	return ctx.findTab(string(page.TargetID))
+}
+
+// NewTab opens a tab with the url, an empty url opens a blank tab. The target is created and activated
+// outside of the state lock with the calls bound to reqCtx
+func (ctx *Context) NewTab(reqCtx context.Context, url string, activate bool) (*Tab, error) {
+	ctx.stateLock.Lock()
+	err := ctx.ensureBrowser(false)
+	browser := ctx.browser
+	ctx.stateLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := openPage(reqCtx, browser, url)
+	if err != nil {
+		return nil, err
+	}
+	ctx.stateLock.Lock()
+	if ctx.browser != browser {
+		ctx.stateLock.Unlock()
+		return nil, errors.New("the browser was closed while opening the tab")
+	}
+	tab := ctx.registerTab(page)
+	activate = activate || ctx.activeTab == nil
+	if activate {
+		ctx.activeTab = tab
+	}
+	ctx.stateLock.Unlock()
+
+	if activate {
+		_, _ = tab.Page.Context(reqCtx).Activate()
+	}
+	return tab, nil
+}
+
+// SelectTab makes the tab with the id the active tab, the tab is brought to the front with the call
+// bound to reqCtx
+func (ctx *Context) SelectTab(reqCtx context.Context, id string) (*Tab, error) {
+	ctx.stateLock.Lock()
+	tab := ctx.findTab(id)
+	ctx.stateLock.Unlock()
+	if tab == nil {
+		return nil, errors.Errorf("tab %s not found", id)
+	}
+	if _, err := tab.Page.Context(reqCtx).Activate(); err != nil {
+		return nil, errors.Wrapf(err, "activate tab %s failed", id)
+	}
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	if ctx.findTab(id) != tab {
+		return nil, errors.Errorf("tab %s was closed", id)
+	}
+	ctx.activeTab = tab
+	return tab, nil
+}
+
+// CloseTab closes the tab with the id, an empty id closes the active tab. The tab is forgotten right
+// away and the target is closed outside of the state lock with the call bound to reqCtx
+func (ctx *Context) CloseTab(reqCtx context.Context, id string) error {
+	ctx.stateLock.Lock()
+	tab := ctx.activeTab
+	if id != "" {
+		tab = ctx.findTab(id)
+	}
+	if tab != nil {
+		ctx.forgetTab(tab)
+	}
+	ctx.stateLock.Unlock()
+	if tab == nil {
+		return errors.Errorf("tab %s not found", id)
+	}
+	if err := tab.Page.Context(reqCtx).Close(); err != nil {
+		return errors.Wrap(err, "close page failed")
+	}
+	return nil
+}
+
+func (ctx *Context) findTab(id string) *Tab {
+	for _, tab := range ctx.tabs {
+		if tab.ID == id {
+			return tab
+		}
+	}
+	return nil
+}
+
+func (ctx *Context) createTab(urls ...string) (*Tab, error) {
+	page, err := ctx.createPage(urls...)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.registerTab(page), nil
+}
+
+// registerTab tracks the page as a tab, a page already tracked returns its tab
+func (ctx *Context) registerTab(page *rod.Page) *Tab {
+	if tab := ctx.findTab(string(page.TargetID)); tab != nil {
+		return tab
+	}
+	tab := &Tab{
+		ID:      string(page.TargetID),
+		Page:    page,
+		Refs:    newElementRefs(),
+		Pointer: newPointer(),
+	}
+	tab.stopEvents = ctx.watchTab(tab)
+	ctx.tabs = append(ctx.tabs, tab)
+	return tab
+}
+
+// forgetTab stops tracking the tab, the active tab falls back to the newest remaining tab
+func (ctx *Context) forgetTab(tab *Tab) {
+	tab.stopEvents()
+	for i, t := range ctx.tabs {
+		if t == tab {
+			ctx.tabs = append(ctx.tabs[:i], ctx.tabs[i+1:]...)
+			break
+		}
+	}
+	if ctx.activeTab == tab {
+		ctx.activeTab = nil
+		if len(ctx.tabs) > 0 {
+			ctx.activeTab = ctx.tabs[len(ctx.tabs)-1]
+		}
+	}
+}
+
+func (ctx *Context) closeTab(tab *Tab) error {
+	ctx.forgetTab(tab)
+	err := tab.Page.Close()
+	if err != nil {
+		return errors.Wrap(err, "close page failed")
+	}
+	return nil
+}
+
+// watchTargets keeps the tabs in sync with the browser, pages opened by the browser itself such as
+// popups are registered and the tabs closed from the browser window are forgotten. A crashed tab is
+// forgotten too and a lost connection to the browser forgets the browser so that it is relaunched
+func (ctx *Context) watchTargets() {
+	browser := ctx.browser
+	eventCtx, cancel := context.WithCancel(ctx.stdContext)
+	ctx.stopTargets = cancel
+
+	_ = proto.TargetSetDiscoverTargets{Discover: true}.Call(browser)
+	wait := browser.Context(eventCtx).EachEvent(func(e *proto.TargetTargetCreated) {
+		if e.TargetInfo.Type != proto.TargetTargetInfoTypePage {
+			return
+		}
+		// the events of the whole browser are received, skip the pages of other incognito contexts
+		if browser.BrowserContextID != "" && e.TargetInfo.BrowserContextID != browser.BrowserContextID {
+			return
+		}
+		// the state lock may be held by the call that created the target, do not block the event loop
+		go ctx.adoptTarget(browser, e.TargetInfo.TargetID, e.TargetInfo.OpenerID)
+	}, func(e *proto.TargetTargetDestroyed) {
+		go ctx.forgetTarget(browser, e.TargetID)
+	}, func(e *proto.TargetTargetCrashed) {
+		go ctx.tabCrashed(browser, e.TargetID, e.Status)
+	})
+	go func() {
+		wait()
+		// the events end before the watch is stopped only when the connection is gone
+		if eventCtx.Err() == nil {
+			ctx.browserLost(browser, "the connection to the browser was lost")
+		}
+	}()
+}
+
+func (ctx *Context) adoptTarget(browser *rod.Browser, targetID, openerID proto.TargetTargetID) {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	if ctx.browser != browser || ctx.findTab(string(targetID)) != nil {
+		return
+	}
+	opener := ctx.findTab(string(openerID))
+	// the pages of a shared remote browser are only taken over when asked to, popups of our tabs always are
+	if ctx.disconnect != nil && !ctx.config.AdoptExistingTabs && opener == nil {
+		return
+	}
+	page, err := browser.PageFromTarget(targetID)
+	if err != nil {
+		return
+	}
+	tab := ctx.registerTab(page)
+	tab.external = ctx.disconnect != nil && (opener == nil || opener.external)
+	if openerID != "" || ctx.activeTab == nil {
+		ctx.activeTab = tab
+	}
+}
+
+func (ctx *Context) forgetTarget(browser *rod.Browser, targetID proto.TargetTargetID) {
+	ctx.stateLock.Lock()
+	defer ctx.stateLock.Unlock()
+	if ctx.browser != browser {
+		return
+	}
+	if tab := ctx.findTab(string(targetID)); tab != nil {
+		ctx.forgetTab(tab)
+	}
+}
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 func PathExists(path string) (bool, error) {
@@ -23,3 +25,19 @@ func FileName(path string) string {
 	}
 	return ""
 }
+
+// SafeJoin joins the relative path onto base and makes sure the result does not escape base
+func SafeJoin(base, rel string) (string, error) {
+	absBase, err := filepath.Abs(base)
+	if err != nil {
+		return "", err
+	}
+	if filepath.IsAbs(rel) {
+		rel = strings.TrimPrefix(rel, filepath.VolumeName(rel))
+	}
+	joined := filepath.Join(absBase, rel)
+	if joined != absBase && !strings.HasPrefix(joined, absBase+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s escapes directory %s", rel, base)
+	}
+	return joined, nil
+}